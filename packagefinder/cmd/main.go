@@ -5,11 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 
+	"github.com/user/packagefinder/internal/lsp"
 	"github.com/user/packagefinder/pkg/finder"
 )
 
 func main() {
+	// "packagefinder lsp [options]" runs the Language Server instead of a
+	// one-shot scan; it's dispatched before flag.Parse() since its flag set
+	// (just --project) is narrower than the scanner's.
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		runLSP(os.Args[2:])
+		return
+	}
+
 	// Define command line arguments
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options] package-name\n\n", os.Args[0])
@@ -19,6 +29,11 @@ func main() {
 
 	outputFile := flag.String("output", "", "Output file path for JSON results (default: print to console)")
 	projectDir := flag.String("project", ".", "Project root directory to analyze")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "Number of files to scan in parallel")
+	traceFile := flag.String("trace", "", "Write a Chrome Trace Event Format JSON report of per-file scan timings to this path")
+	traceSummary := flag.Bool("trace-summary", false, "Print the slowest scanned files to stderr")
+	cacheMode := flag.String("cache", "on", "Cache mode: on, off, or refresh")
+	cacheDir := flag.String("cache-dir", "", "On-disk cache directory (default: a global cache under the user's cache directory, shared across projects)")
 	flag.Parse()
 
 	// Check if package name is provided
@@ -30,6 +45,14 @@ func main() {
 
 	packageName := flag.Arg(0)
 
+	mode := finder.CacheMode(*cacheMode)
+	switch mode {
+	case finder.CacheOn, finder.CacheOff, finder.CacheRefresh:
+	default:
+		fmt.Printf("Error: --cache must be one of on, off, or refresh, got %q\n", *cacheMode)
+		os.Exit(1)
+	}
+
 	// Resolve absolute path of project directory
 	absProjectDir, err := filepath.Abs(*projectDir)
 	if err != nil {
@@ -39,12 +62,44 @@ func main() {
 
 	fmt.Printf("Analyzing usage of package '%s' in project at %s...\n", packageName, absProjectDir)
 
-	// Run the analysis
-	results, err := finder.FindPackageUsage(absProjectDir, packageName)
+	// Run the analysis. FindPackageUsage's default Pipeline settings don't
+	// expose --concurrency/--trace, so drive a Pipeline directly per its
+	// doc comment.
+	pipeline := finder.NewPipeline(absProjectDir, packageName)
+	pipeline.Concurrency = *concurrency
+	pipeline.CacheMode = mode
+	pipeline.CacheDir = *cacheDir
+	if pipeline.CacheDir == "" {
+		if dir, err := finder.DefaultGlobalCacheDir(); err == nil {
+			pipeline.CacheDir = dir
+		}
+	}
+	if *traceFile != "" || *traceSummary {
+		pipeline.Trace = finder.NewTrace()
+	}
+
+	results, err := pipeline.Run()
 	if err != nil {
 		fmt.Printf("Error analyzing package usage: %v\n", err)
 		os.Exit(1)
 	}
+	if results == nil {
+		results = []finder.PackageUsage{}
+	}
+
+	if pipeline.Trace != nil {
+		if *traceFile != "" {
+			if err := writeTraceFile(pipeline.Trace, *traceFile); err != nil {
+				fmt.Printf("Error writing trace to %s: %v\n", *traceFile, err)
+				os.Exit(1)
+			}
+			fmt.Printf("Trace written to %s\n", *traceFile)
+		}
+		if *traceSummary {
+			fmt.Fprintln(os.Stderr, "Slowest scanned files:")
+			pipeline.Trace.WriteSummary(os.Stderr, 10)
+		}
+	}
 
 	// Output the results
 	if *outputFile != "" {
@@ -58,3 +113,34 @@ func main() {
 		finder.PrintResults(results)
 	}
 }
+
+// runLSP serves the Language Server Protocol described in internal/lsp over
+// stdio, the same server cmd/ts-diff-lsp runs as its own dedicated binary -
+// this subcommand exists so editors and CI bots that already invoke
+// "packagefinder" don't need a second binary on PATH just to get LSP mode.
+func runLSP(args []string) {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	projectDir := fs.String("project", ".", "Project root directory to serve")
+	fs.Parse(args)
+
+	absProjectDir, err := filepath.Abs(*projectDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving project path: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := lsp.NewServer(absProjectDir)
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "packagefinder lsp: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func writeTraceFile(trace *finder.Trace, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return trace.WriteJSON(f)
+}
@@ -0,0 +1,32 @@
+// Command ts-diff-lsp runs packagefinder as a Language Server, serving
+// textDocument/references, textDocument/documentSymbol, workspace/symbol,
+// and textDocument/codeAction over the stdio JSON-RPC transport LSP
+// clients speak. It's a thin binary: all the protocol and analysis logic
+// lives in internal/lsp.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/user/packagefinder/internal/lsp"
+)
+
+func main() {
+	projectDir := flag.String("project", ".", "Project root directory to serve")
+	flag.Parse()
+
+	absProjectDir, err := filepath.Abs(*projectDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving project path: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := lsp.NewServer(absProjectDir)
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "ts-diff-lsp: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,118 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// uriToPath strips the "file://" scheme LSP clients send URIs with. This
+// server only ever runs against local files, so anything else is returned
+// unchanged rather than rejected - callers that pass a bad URI will simply
+// fail the subsequent file read with a clear error.
+func uriToPath(uri string) string {
+	return strings.TrimPrefix(uri, "file://")
+}
+
+// pathToURI is uriToPath's inverse.
+func pathToURI(path string) string {
+	if strings.HasPrefix(path, "file://") {
+		return path
+	}
+	return "file://" + path
+}
+
+// offsetToPosition converts a byte offset into content to a zero-based
+// line/UTF-16-character Position, the unit LSP positions are specified in
+// regardless of the transport encoding. finder.Location already carries a
+// 1-based line number computed the same way this walks content, so the two
+// stay in agreement - see locationToPosition below for the glue.
+func offsetToPosition(content string, offset int) Position {
+	if offset > len(content) {
+		offset = len(content)
+	}
+
+	line := 0
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if content[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+
+	return Position{Line: line, Character: utf16Len(content[lineStart:offset])}
+}
+
+// positionToOffset is offsetToPosition's inverse: it finds the byte offset
+// in content that Position names.
+func positionToOffset(content string, pos Position) int {
+	line := 0
+	i := 0
+	for line < pos.Line && i < len(content) {
+		if content[i] == '\n' {
+			line++
+		}
+		i++
+	}
+	if line < pos.Line {
+		return len(content) // Position is past the end of content
+	}
+
+	lineEnd := strings.IndexByte(content[i:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(content)
+	} else {
+		lineEnd += i
+	}
+
+	return i + byteOffsetForUTF16Count(content[i:lineEnd], pos.Character)
+}
+
+// utf16Len reports how many UTF-16 code units s decodes to, which is what
+// LSP's Character field counts (a non-BMP rune, e.g. most emoji, counts as
+// two).
+func utf16Len(s string) int {
+	count := 0
+	for _, r := range s {
+		count += len(utf16.Encode([]rune{r}))
+	}
+	return count
+}
+
+// byteOffsetForUTF16Count returns the byte offset within line corresponding
+// to utf16Count UTF-16 code units, clamping to len(line) if the line is
+// shorter than that.
+func byteOffsetForUTF16Count(line string, utf16Count int) int {
+	units := 0
+	for i, r := range line {
+		if units >= utf16Count {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// identifierAt returns the identifier (word of [A-Za-z0-9_$]) touching
+// offset in content, and its [start, end) byte span, or ok=false if offset
+// isn't within or adjacent to one.
+func identifierAt(content string, offset int) (name string, start, end int, ok bool) {
+	isIdentByte := func(b byte) bool {
+		return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+	}
+
+	if offset > len(content) {
+		offset = len(content)
+	}
+	start, end = offset, offset
+	for start > 0 && isIdentByte(content[start-1]) {
+		start--
+	}
+	for end < len(content) && isIdentByte(content[end]) {
+		end++
+	}
+	if start == end {
+		return "", 0, 0, false
+	}
+	return content[start:end], start, end, true
+}
@@ -0,0 +1,230 @@
+// Package lsp wraps pkg/finder's analysis in a Language Server Protocol
+// front-end: a Server that answers textDocument/references,
+// textDocument/documentSymbol, workspace/symbol, and
+// textDocument/codeAction requests over the JSON-RPC 2.0 stdio transport
+// LSP clients speak. It reuses finder.NewASTParser, analysis.Project,
+// and internal/parser's edit primitives rather than re-implementing
+// import parsing, the same way gopls' internal/lsp/source layer sits on
+// top of go/analysis instead of duplicating it.
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/user/packagefinder/pkg/finder"
+)
+
+// Position is a zero-based line/character position. Character counts
+// UTF-16 code units, per the LSP spec - see ToLSPPosition in convert.go
+// for the conversion from a byte offset in source text.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a half-open [Start, End) span of Positions.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location points at a Range within a file, identified by its URI.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// TextEdit replaces the text within Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit is a set of TextEdits to apply, grouped by the URI of the
+// file they belong to.
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// SymbolKind mirrors the subset of the LSP SymbolKind enum this server
+// reports; imported bindings are modeled as modules/variables depending on
+// what they bind to.
+type SymbolKind int
+
+const (
+	SymbolKindModule   SymbolKind = 2
+	SymbolKindVariable SymbolKind = 13
+)
+
+// SymbolInformation is one workspace/symbol result.
+type SymbolInformation struct {
+	Name     string     `json:"name"`
+	Kind     SymbolKind `json:"kind"`
+	Location Location   `json:"location"`
+}
+
+// DocumentSymbol is one textDocument/documentSymbol result: a binding
+// introduced by an import/require statement in the requested file.
+type DocumentSymbol struct {
+	Name           string     `json:"name"`
+	Detail         string     `json:"detail,omitempty"`
+	Kind           SymbolKind `json:"kind"`
+	Range          Range      `json:"range"`
+	SelectionRange Range      `json:"selectionRange"`
+}
+
+// CodeAction is one textDocument/codeAction result: a named, immediately
+// applicable fix.
+type CodeAction struct {
+	Title string         `json:"title"`
+	Kind  string         `json:"kind"`
+	Edit  *WorkspaceEdit `json:"edit,omitempty"`
+}
+
+// TextDocumentIdentifier names the file a request targets by URI.
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier additionally carries the document
+// version a didChange notification applies to, so the Snapshot can tell a
+// stale edit from the current one.
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentPositionParams is the common shape of references,
+// documentSymbol-adjacent, and hover-style requests: a file plus a cursor
+// position within it.
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// ReferenceParams is textDocument/references' request shape.
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+// DocumentSymbolParams is textDocument/documentSymbol's request shape.
+type DocumentSymbolParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// WorkspaceSymbolParams is workspace/symbol's request shape.
+type WorkspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+// CodeActionParams is textDocument/codeAction's request shape.
+type CodeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+// DidOpenTextDocumentParams carries a file's initial content and version.
+type DidOpenTextDocumentParams struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+		Text    string `json:"text"`
+	} `json:"textDocument"`
+}
+
+// DidChangeTextDocumentParams carries a file's full new content and
+// version; this server only supports whole-document sync
+// (TextDocumentSyncKindFull), not incremental ranges.
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// FileChangeType mirrors the subset of the LSP FileChangeType enum
+// workspace/didChangeWatchedFiles reports.
+type FileChangeType int
+
+const (
+	FileChangeCreated FileChangeType = 1
+	FileChangeChanged FileChangeType = 2
+	FileChangeDeleted FileChangeType = 3
+)
+
+// FileEvent is one entry of workspace/didChangeWatchedFiles' changes array.
+type FileEvent struct {
+	URI  string         `json:"uri"`
+	Type FileChangeType `json:"type"`
+}
+
+// DidChangeWatchedFilesParams is workspace/didChangeWatchedFiles'
+// notification shape, sent for files the client isn't editing directly
+// (didOpen/didChange already cover open buffers).
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
+// FindUsagesParams is packagefinder/findUsages' request shape: the package
+// to search for, optionally scoped to a file or directory. Version is
+// accepted for callers that track one but isn't matched against anything -
+// finder's usage analysis is syntactic, not version-aware, the same
+// limitation finder.PackageUsage itself has.
+type FindUsagesParams struct {
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// FindUsagesResult is packagefinder/findUsages' response shape.
+type FindUsagesResult struct {
+	Package string                `json:"package"`
+	Version string                `json:"version,omitempty"`
+	Usages  []finder.PackageUsage `json:"usages"`
+}
+
+// ListPackagesResult is packagefinder/listPackages' response shape: every
+// distinct package specifier imported anywhere in the workspace.
+type ListPackagesResult struct {
+	Packages []string `json:"packages"`
+}
+
+// WhyImportParams is packagefinder/whyImport's request shape: the file the
+// workspace is entered from and the file whose import chain is being
+// explained.
+type WhyImportParams struct {
+	Entry string `json:"entry"`
+	Path  string `json:"path"`
+}
+
+// WhyImportResult is packagefinder/whyImport's response shape: the files on
+// the path from Entry to Path, inclusive of both, in traversal order. Chain
+// is empty if Path isn't reachable from Entry.
+type WhyImportResult struct {
+	Chain []string `json:"chain"`
+}
+
+// rawMessage is the JSON-RPC 2.0 envelope shared by requests and
+// notifications; ID is omitted (nil) on notifications.
+type rawMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// responseMessage is the JSON-RPC 2.0 envelope for a request's reply.
+type responseMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
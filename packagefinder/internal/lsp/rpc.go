@@ -0,0 +1,89 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// readMessage reads one `Content-Length`-framed JSON-RPC message from r,
+// the same framing every LSP client/server speaks over stdio.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	var length int
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break // blank line ends the header block
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("lsp: invalid Content-Length %q: %w", value, err)
+			}
+		}
+	}
+	if length == 0 {
+		return nil, fmt.Errorf("lsp: message had no Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage frames payload with a Content-Length header and writes it
+// to w.
+func writeMessage(w io.Writer, payload []byte) error {
+	_, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(payload), payload)
+	return err
+}
+
+// Serve reads JSON-RPC requests and notifications from r and writes
+// responses to w until r is exhausted or returns an error other than EOF.
+// Each message is handled synchronously and in arrival order, which is
+// sufficient for this server's in-memory Snapshot (there's no concurrent
+// I/O to overlap).
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var msg rawMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			continue // not a well-formed JSON-RPC message; nothing to reply to
+		}
+
+		result, rpcErr := s.handle(msg.Method, msg.Params)
+		if msg.ID == nil {
+			continue // notification: no response expected
+		}
+
+		resp := responseMessage{JSONRPC: "2.0", ID: msg.ID, Result: result}
+		if rpcErr != nil {
+			resp.Result = nil
+			resp.Error = &responseError{Code: -32603, Message: rpcErr.Error()}
+		}
+		payload, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		if err := writeMessage(w, payload); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,549 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/packagefinder/internal/parser"
+	"github.com/user/packagefinder/pkg/finder"
+	"github.com/user/packagefinder/pkg/finder/analysis"
+)
+
+// Server answers LSP requests against a single workspace root, backed by a
+// Snapshot of its files.
+type Server struct {
+	snapshot *Snapshot
+}
+
+// NewServer returns a Server rooted at root. Call Serve to start handling
+// requests over a transport.
+func NewServer(root string) *Server {
+	return &Server{snapshot: NewSnapshot(root)}
+}
+
+// handle dispatches method to its handler and marshals params into the
+// shape that handler expects. Unknown methods and notifications this
+// server doesn't act on (e.g. textDocument/didSave) are no-ops that
+// return a nil result rather than an error, since an LSP client treats an
+// unhandled notification as fine to ignore.
+func (s *Server) handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		return s.initialize()
+	case "textDocument/didOpen":
+		return nil, s.didOpen(params)
+	case "textDocument/didChange":
+		return nil, s.didChange(params)
+	case "textDocument/didClose":
+		return nil, s.didClose(params)
+	case "workspace/didChangeWatchedFiles":
+		return nil, s.didChangeWatchedFiles(params)
+	case "textDocument/references":
+		return s.references(params)
+	case "textDocument/documentSymbol":
+		return s.documentSymbol(params)
+	case "workspace/symbol":
+		return s.workspaceSymbol(params)
+	case "textDocument/codeAction":
+		return s.codeAction(params)
+	case "packagefinder/findUsages":
+		return s.findUsages(params)
+	case "packagefinder/listPackages":
+		return s.listPackages(params)
+	case "packagefinder/whyImport":
+		return s.whyImport(params)
+	default:
+		return nil, nil
+	}
+}
+
+func (s *Server) initialize() (interface{}, error) {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync":        1, // TextDocumentSyncKindFull
+			"referencesProvider":      true,
+			"documentSymbolProvider":  true,
+			"workspaceSymbolProvider": true,
+			"codeActionProvider":      true,
+			// Custom, non-standard methods this server also answers;
+			// advertised under "experimental" the way gopls does for its
+			// own gopls/* extensions, since the LSP spec reserves the
+			// top-level capability keys for methods it defines itself.
+			"experimental": map[string]interface{}{
+				"packagefinderFindUsages":   true,
+				"packagefinderListPackages": true,
+				"packagefinderWhyImport":    true,
+			},
+		},
+	}, nil
+}
+
+func (s *Server) didOpen(raw json.RawMessage) error {
+	var params DidOpenTextDocumentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	s.snapshot.Open(uriToPath(params.TextDocument.URI), params.TextDocument.Version, params.TextDocument.Text)
+	return nil
+}
+
+func (s *Server) didChange(raw json.RawMessage) error {
+	var params DidChangeTextDocumentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	if len(params.ContentChanges) == 0 {
+		return nil
+	}
+	// Full-document sync: the last entry always holds the entire new text.
+	text := params.ContentChanges[len(params.ContentChanges)-1].Text
+	s.snapshot.Open(uriToPath(params.TextDocument.URI), params.TextDocument.Version, text)
+	return nil
+}
+
+func (s *Server) didClose(raw json.RawMessage) error {
+	var params struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	s.snapshot.Close(uriToPath(params.TextDocument.URI))
+	return nil
+}
+
+// didChangeWatchedFiles implements workspace/didChangeWatchedFiles: it
+// invalidates the cached Project so the next request rebuilds it from
+// disk, and for a deleted file additionally prunes the on-disk
+// finder.Cache (chunk3-4) so packagefinder/findUsages stops serving a
+// stale entry for a path that no longer exists. A changed file needs no
+// such pruning step: the cache is content-addressed by file hash, so its
+// new content simply misses the old entry on the next lookup rather than
+// needing an explicit invalidation.
+//
+// The server doesn't send client/registerCapability itself - rpc.go only
+// answers requests the client initiates, it has no outbound-request
+// support - so this notification only arrives from a client configured to
+// watch the workspace's JS/TS files unconditionally (or one willing to
+// send it speculatively). A client that insists on the server driving
+// dynamic registration won't trigger this path at all.
+func (s *Server) didChangeWatchedFiles(raw json.RawMessage) error {
+	var params DidChangeWatchedFilesParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return err
+	}
+	s.snapshot.Invalidate()
+
+	hasDeletion := false
+	for _, change := range params.Changes {
+		if change.Type == FileChangeDeleted {
+			hasDeletion = true
+			break
+		}
+	}
+	if !hasDeletion {
+		return nil
+	}
+
+	dir, err := finder.DefaultGlobalCacheDir()
+	if err != nil {
+		return nil
+	}
+	cache, err := finder.NewFileCache(dir)
+	if err != nil {
+		return nil
+	}
+	return cache.Prune()
+}
+
+// references implements textDocument/references: it resolves the
+// identifier under the cursor to the binding it came from, then asks the
+// project's analysis.Project for every downstream usage, across every
+// file reached through however many re-export hops lie in between.
+func (s *Server) references(raw json.RawMessage) ([]Location, error) {
+	var params ReferenceParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+
+	path := uriToPath(params.TextDocument.URI)
+	content, err := s.snapshot.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := positionToOffset(content, params.Position)
+	name, _, _, ok := identifierAt(content, offset)
+	if !ok {
+		return nil, nil
+	}
+
+	project, err := s.snapshot.Project()
+	if err != nil {
+		return nil, err
+	}
+	binding, ok := project.BindingFor(path, name)
+	if !ok {
+		return nil, nil
+	}
+
+	usages := project.FindUsagesWithFile(binding.SourceModule, binding.ExportedName)
+	locations := make([]Location, 0, len(usages))
+	for _, u := range usages {
+		fileContent, err := s.snapshot.ReadFile(u.File)
+		if err != nil {
+			continue
+		}
+		locations = append(locations, Location{
+			URI:   pathToURI(u.File),
+			Range: pointRange(locationToPosition(fileContent, u.Location)),
+		})
+	}
+	return locations, nil
+}
+
+// locationToPosition converts an analysis.Location (a 1-based line and a
+// 0-based byte column within that line) to an LSP Position (a 0-based
+// line and a 0-based UTF-16 column).
+func locationToPosition(fileContent string, loc analysis.Location) Position {
+	lines := strings.Split(fileContent, "\n")
+	lineIdx := loc.Line - 1
+	if lineIdx < 0 || lineIdx >= len(lines) {
+		return Position{Line: maxInt(lineIdx, 0)}
+	}
+	line := lines[lineIdx]
+	col := loc.Character
+	if col > len(line) {
+		col = len(line)
+	}
+	return Position{Line: lineIdx, Character: utf16Len(line[:col])}
+}
+
+// pointRange is a zero-width Range at pos, used for results (like
+// references) that name a single point rather than a span.
+func pointRange(pos Position) Range {
+	return Range{Start: pos, End: pos}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// documentSymbol implements textDocument/documentSymbol: one DocumentSymbol
+// per import/require binding the file introduces.
+func (s *Server) documentSymbol(raw json.RawMessage) ([]DocumentSymbol, error) {
+	var params DocumentSymbolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+
+	path := uriToPath(params.TextDocument.URI)
+	content, err := s.snapshot.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parser.ParseSource(path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []DocumentSymbol
+	for _, decl := range file.Imports {
+		rng := Range{Start: offsetToPosition(content, decl.Start), End: offsetToPosition(content, decl.End)}
+		for _, name := range importedNames(decl) {
+			symbols = append(symbols, DocumentSymbol{
+				Name:           name,
+				Detail:         decl.Specifier,
+				Kind:           SymbolKindModule,
+				Range:          rng,
+				SelectionRange: rng,
+			})
+		}
+	}
+	return symbols, nil
+}
+
+// importedNames lists the local names decl binds.
+func importedNames(decl parser.ImportDecl) []string {
+	var names []string
+	if decl.Default != "" {
+		names = append(names, decl.Default)
+	}
+	if decl.Namespace != "" {
+		names = append(names, decl.Namespace)
+	}
+	for _, named := range decl.Named {
+		if named.Alias != "" {
+			names = append(names, named.Alias)
+		} else {
+			names = append(names, named.Name)
+		}
+	}
+	return names
+}
+
+// workspaceSymbol implements workspace/symbol: every binding across the
+// project whose local name contains query (case-insensitive), ranked
+// alphabetically by containing file so results are stable across calls.
+func (s *Server) workspaceSymbol(raw json.RawMessage) ([]SymbolInformation, error) {
+	var params WorkspaceSymbolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+
+	project, err := s.snapshot.Project()
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(params.Query)
+	var symbols []SymbolInformation
+	for _, file := range project.Files() {
+		content, err := s.snapshot.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, b := range project.BindingsIn(file) {
+			if query != "" && !strings.Contains(strings.ToLower(b.LocalName), query) {
+				continue
+			}
+			kind := SymbolKindVariable
+			if b.Kind == analysis.BindingNamespace {
+				kind = SymbolKindModule
+			}
+			symbols = append(symbols, SymbolInformation{
+				Name: b.LocalName,
+				Kind: kind,
+				Location: Location{
+					URI:   pathToURI(file),
+					Range: pointRange(offsetToPosition(content, 0)),
+				},
+			})
+		}
+	}
+	return symbols, nil
+}
+
+// codeAction implements textDocument/codeAction, offering "remove unused
+// import" for any import in range with no usages elsewhere in the file,
+// and "convert to CommonJS require" for an ES6 import - the one style
+// conversion simple enough to do without the full rewrite matrix
+// chunk2-5's codemod package adds (AMD's factory-parameter wiring needs
+// more context than a single import statement carries).
+func (s *Server) codeAction(raw json.RawMessage) ([]CodeAction, error) {
+	var params CodeActionParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+
+	path := uriToPath(params.TextDocument.URI)
+	content, err := s.snapshot.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseSource(path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	startOffset := positionToOffset(content, params.Range.Start)
+	endOffset := positionToOffset(content, params.Range.End)
+
+	var actions []CodeAction
+	for _, decl := range file.Imports {
+		if decl.End < startOffset || decl.Start > endOffset {
+			continue
+		}
+
+		if isUnused(content, decl) {
+			edits := parser.RemoveImport(file, decl)
+			actions = append(actions, CodeAction{
+				Title: "Remove unused import '" + decl.Specifier + "'",
+				Kind:  "quickfix",
+				Edit:  workspaceEdit(params.TextDocument.URI, content, edits),
+			})
+		}
+
+		if requireStmt, ok := convertToRequire(decl); ok {
+			edit := parser.Edit{Start: decl.Start, End: decl.End, NewText: requireStmt}
+			actions = append(actions, CodeAction{
+				Title: "Convert to CommonJS require",
+				Kind:  "refactor.rewrite",
+				Edit:  workspaceEdit(params.TextDocument.URI, content, []parser.Edit{edit}),
+			})
+		}
+	}
+	return actions, nil
+}
+
+// isUnused reports whether none of decl's bound names appear anywhere in
+// content outside of decl's own statement.
+func isUnused(content string, decl parser.ImportDecl) bool {
+	names := importedNames(decl)
+	if decl.IsSideEffect || len(names) == 0 {
+		return false
+	}
+	before := content[:decl.Start]
+	after := content[decl.End:]
+	for _, name := range names {
+		if containsIdentifier(before, name) || containsIdentifier(after, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsIdentifier(text, name string) bool {
+	idx := 0
+	for {
+		i := strings.Index(text[idx:], name)
+		if i < 0 {
+			return false
+		}
+		pos := idx + i
+		before := byte(0)
+		if pos > 0 {
+			before = text[pos-1]
+		}
+		after := byte(0)
+		if pos+len(name) < len(text) {
+			after = text[pos+len(name)]
+		}
+		if !isIdentByte(before) && !isIdentByte(after) {
+			return true
+		}
+		idx = pos + len(name)
+	}
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// convertToRequire renders decl as an equivalent `const ... = require(...)`
+// statement, for the default-only, named-only, and default+named shapes.
+// Namespace imports (`import * as X`) and type-only imports have no clean
+// CommonJS equivalent and are left alone (ok=false).
+func convertToRequire(decl parser.ImportDecl) (string, bool) {
+	if decl.IsTypeOnly || decl.IsSideEffect || decl.Namespace != "" {
+		return "", false
+	}
+
+	requireExpr := "require('" + decl.Specifier + "')"
+
+	switch {
+	case decl.Default != "" && len(decl.Named) == 0:
+		return "const " + decl.Default + " = " + requireExpr + ";", true
+	case decl.Default == "" && len(decl.Named) > 0:
+		return "const { " + joinNamed(decl.Named) + " } = " + requireExpr + ";", true
+	case decl.Default != "" && len(decl.Named) > 0:
+		return "const " + decl.Default + " = " + requireExpr + ";\n" +
+			"const { " + joinNamed(decl.Named) + " } = " + decl.Default + ";", true
+	default:
+		return "", false
+	}
+}
+
+func joinNamed(named []parser.NamedImport) string {
+	parts := make([]string, 0, len(named))
+	for _, n := range named {
+		if n.Alias != "" {
+			parts = append(parts, n.Name+": "+n.Alias)
+		} else {
+			parts = append(parts, n.Name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// workspaceEdit converts internal/parser.Edits (byte-offset replacements
+// in content) into an LSP WorkspaceEdit for the single file at uri.
+func workspaceEdit(uri, content string, edits []parser.Edit) *WorkspaceEdit {
+	textEdits := make([]TextEdit, 0, len(edits))
+	for _, e := range edits {
+		textEdits = append(textEdits, TextEdit{
+			Range:   Range{Start: offsetToPosition(content, e.Start), End: offsetToPosition(content, e.End)},
+			NewText: e.NewText,
+		})
+	}
+	return &WorkspaceEdit{Changes: map[string][]TextEdit{uri: textEdits}}
+}
+
+// findUsages implements packagefinder/findUsages: runs finder's usage
+// pipeline for params.Package against the workspace root, reusing the
+// on-disk FileCache (chunk3-4) so unaffected files' cached entries aren't
+// re-parsed, then narrows to params.Path if given.
+func (s *Server) findUsages(raw json.RawMessage) (*FindUsagesResult, error) {
+	var params FindUsagesParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Package == "" {
+		return nil, fmt.Errorf("lsp: packagefinder/findUsages requires a non-empty package")
+	}
+
+	pipeline := finder.NewPipeline(s.snapshot.root, params.Package)
+	if dir, err := finder.DefaultGlobalCacheDir(); err == nil {
+		pipeline.CacheDir = dir
+	}
+	usages, err := pipeline.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Path != "" {
+		target := uriToPath(params.Path)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(s.snapshot.root, target)
+		}
+		var scoped []finder.PackageUsage
+		for _, u := range usages {
+			if u.FileName == target || strings.HasPrefix(u.FileName, target+string(filepath.Separator)) {
+				scoped = append(scoped, u)
+			}
+		}
+		usages = scoped
+	}
+
+	return &FindUsagesResult{Package: params.Package, Version: params.Version, Usages: usages}, nil
+}
+
+// listPackages implements packagefinder/listPackages: every distinct
+// package specifier imported anywhere in the workspace.
+func (s *Server) listPackages(raw json.RawMessage) (*ListPackagesResult, error) {
+	packages, err := finder.ListPackages(s.snapshot.root)
+	if err != nil {
+		return nil, err
+	}
+	return &ListPackagesResult{Packages: packages}, nil
+}
+
+// whyImport implements packagefinder/whyImport: builds a fresh
+// finder.ProjectGraph for the workspace and returns the chain of imports
+// connecting params.Entry to params.Path.
+func (s *Server) whyImport(raw json.RawMessage) (*WhyImportResult, error) {
+	var params WhyImportParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Entry == "" || params.Path == "" {
+		return nil, fmt.Errorf("lsp: packagefinder/whyImport requires both entry and path")
+	}
+
+	graph, err := finder.BuildProjectGraph(s.snapshot.root)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, ok := graph.ImportChain(uriToPath(params.Entry), uriToPath(params.Path))
+	if !ok {
+		return &WhyImportResult{}, nil
+	}
+	return &WhyImportResult{Chain: chain}, nil
+}
@@ -0,0 +1,217 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestReferencesFindsCrossFileUsage(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-lsp-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTempFile(t, tempDir, "index.ts", "export { debounce } from 'lodash';\n")
+	consumerSrc := "import { debounce } from './index';\n" +
+		"const run = debounce(fn, 10);\n"
+	consumerPath := writeTempFile(t, tempDir, "consumer.ts", consumerSrc)
+
+	server := NewServer(tempDir)
+	params, _ := json.Marshal(ReferenceParams{
+		TextDocumentPositionParams: TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: pathToURI(consumerPath)},
+			Position:     Position{Line: 0, Character: 9}, // inside "debounce" in the import clause
+		},
+	})
+
+	result, err := server.handle("textDocument/references", params)
+	if err != nil {
+		t.Fatalf("references failed: %v", err)
+	}
+	locations, ok := result.([]Location)
+	if !ok {
+		t.Fatalf("expected []Location, got %T", result)
+	}
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 usage, got %d: %+v", len(locations), locations)
+	}
+	if locations[0].URI != pathToURI(consumerPath) {
+		t.Errorf("expected usage in consumer.ts, got %q", locations[0].URI)
+	}
+	if locations[0].Range.Start.Line != 1 {
+		t.Errorf("expected usage on line 1 (0-based), got %d", locations[0].Range.Start.Line)
+	}
+}
+
+func TestDocumentSymbolListsImportedBindings(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-lsp-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := writeTempFile(t, tempDir, "app.ts", "import React, { useState } from 'react';\n")
+
+	server := NewServer(tempDir)
+	params, _ := json.Marshal(DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: pathToURI(path)}})
+
+	result, err := server.handle("textDocument/documentSymbol", params)
+	if err != nil {
+		t.Fatalf("documentSymbol failed: %v", err)
+	}
+	symbols, ok := result.([]DocumentSymbol)
+	if !ok {
+		t.Fatalf("expected []DocumentSymbol, got %T", result)
+	}
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %d: %+v", len(symbols), symbols)
+	}
+}
+
+func TestCodeActionRemovesUnusedImport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-lsp-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := "import unused from 'left-pad';\nconsole.log('hi');\n"
+	path := writeTempFile(t, tempDir, "app.ts", src)
+
+	server := NewServer(tempDir)
+	params, _ := json.Marshal(CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: pathToURI(path)},
+		Range:        Range{Start: Position{Line: 0}, End: Position{Line: 0, Character: len("import unused from 'left-pad';")}},
+	})
+
+	result, err := server.handle("textDocument/codeAction", params)
+	if err != nil {
+		t.Fatalf("codeAction failed: %v", err)
+	}
+	actions, ok := result.([]CodeAction)
+	if !ok {
+		t.Fatalf("expected []CodeAction, got %T", result)
+	}
+
+	var found bool
+	for _, a := range actions {
+		if a.Title == "Remove unused import 'left-pad'" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a remove-unused-import action, got %+v", actions)
+	}
+}
+
+func TestFindUsagesScopesToPath(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-lsp-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTempFile(t, tempDir, "a.ts", "import { debounce } from 'lodash';\n")
+	bPath := writeTempFile(t, tempDir, "b.ts", "import { throttle } from 'lodash';\n")
+
+	server := NewServer(tempDir)
+	params, _ := json.Marshal(FindUsagesParams{Package: "lodash", Path: bPath})
+
+	result, err := server.handle("packagefinder/findUsages", params)
+	if err != nil {
+		t.Fatalf("findUsages failed: %v", err)
+	}
+	res, ok := result.(*FindUsagesResult)
+	if !ok {
+		t.Fatalf("expected *FindUsagesResult, got %T", result)
+	}
+	if len(res.Usages) != 1 {
+		t.Fatalf("expected 1 usage scoped to b.ts, got %d: %+v", len(res.Usages), res.Usages)
+	}
+	if res.Usages[0].FileName != bPath {
+		t.Errorf("expected usage in %s, got %s", bPath, res.Usages[0].FileName)
+	}
+}
+
+func TestListPackagesCollectsBareSpecifiers(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-lsp-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTempFile(t, tempDir, "a.ts", "import React from 'react';\nimport './local';\n")
+	writeTempFile(t, tempDir, "b.ts", "const { debounce } = require('lodash');\n")
+
+	server := NewServer(tempDir)
+	result, err := server.handle("packagefinder/listPackages", nil)
+	if err != nil {
+		t.Fatalf("listPackages failed: %v", err)
+	}
+	res, ok := result.(*ListPackagesResult)
+	if !ok {
+		t.Fatalf("expected *ListPackagesResult, got %T", result)
+	}
+	if len(res.Packages) != 2 || res.Packages[0] != "lodash" || res.Packages[1] != "react" {
+		t.Fatalf("expected [lodash react], got %+v", res.Packages)
+	}
+}
+
+func TestWhyImportReturnsChain(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-lsp-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	entryPath := writeTempFile(t, tempDir, "index.ts", "import './routes';\n")
+	routesPath := writeTempFile(t, tempDir, "routes.ts", "import './routes/users';\n")
+	if err := os.MkdirAll(filepath.Join(tempDir, "routes"), 0755); err != nil {
+		t.Fatalf("failed to create routes dir: %v", err)
+	}
+	usersPath := writeTempFile(t, tempDir, "routes/users.ts", "console.log('users');\n")
+
+	server := NewServer(tempDir)
+	params, _ := json.Marshal(WhyImportParams{Entry: entryPath, Path: usersPath})
+
+	result, err := server.handle("packagefinder/whyImport", params)
+	if err != nil {
+		t.Fatalf("whyImport failed: %v", err)
+	}
+	res, ok := result.(*WhyImportResult)
+	if !ok {
+		t.Fatalf("expected *WhyImportResult, got %T", result)
+	}
+	want := []string{entryPath, routesPath, usersPath}
+	if len(res.Chain) != len(want) {
+		t.Fatalf("expected chain %v, got %v", want, res.Chain)
+	}
+	for i, file := range want {
+		if res.Chain[i] != file {
+			t.Errorf("chain[%d] = %q, want %q", i, res.Chain[i], file)
+		}
+	}
+}
+
+func TestOffsetPositionRoundTrip(t *testing.T) {
+	content := "line one\nline two\nline three"
+	for _, offset := range []int{0, 5, 9, 18, len(content)} {
+		pos := offsetToPosition(content, offset)
+		back := positionToOffset(content, pos)
+		if back != offset {
+			t.Errorf("offsetToPosition/positionToOffset round-trip failed for offset %d: got %d via %+v", offset, back, pos)
+		}
+	}
+}
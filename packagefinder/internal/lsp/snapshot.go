@@ -0,0 +1,107 @@
+package lsp
+
+import (
+	"os"
+	"sync"
+
+	"github.com/user/packagefinder/pkg/finder/analysis"
+)
+
+// overlay is one open file's editor-owned content, as of the last
+// didOpen/didChange the client sent.
+type overlay struct {
+	version int
+	content string
+}
+
+// Snapshot is the server's cached view of the workspace: every open
+// buffer's content plus a lazily (re)built analysis.Project. Invalidation
+// is keyed by file version - didOpen/didChange bump a file's version and
+// mark the cached Project stale, so the next request that needs the
+// project rebuilds it once no matter how many files changed in between,
+// rather than re-parsing the workspace on every request.
+//
+// analysis.BuildProject only reads from disk, so an open buffer's
+// unsaved edits aren't reflected in the rebuilt Project - readFile below
+// prefers the overlay for anything that needs a single file's current
+// text (references, documentSymbol, codeAction), but workspace/symbol and
+// cross-file FindUsages still see the last-saved content for a dirty
+// file. That mirrors gopls' distinction between a file's "current
+// content" and a possibly-stale cross-package analysis result.
+type Snapshot struct {
+	root string
+
+	mu       sync.Mutex
+	overlays map[string]*overlay // path -> overlay
+	dirty    bool
+	project  *analysis.Project
+}
+
+// NewSnapshot returns a Snapshot rooted at root. The first call that needs
+// a Project builds it.
+func NewSnapshot(root string) *Snapshot {
+	return &Snapshot{root: root, overlays: map[string]*overlay{}, dirty: true}
+}
+
+// Open records path's content at version, as sent by textDocument/didOpen
+// or textDocument/didChange (this server only supports full-document
+// sync, so the latest call always wins).
+func (s *Snapshot) Open(path string, version int, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overlays[path] = &overlay{version: version, content: content}
+	s.dirty = true
+}
+
+// Close drops path's overlay, so future reads fall back to disk.
+func (s *Snapshot) Close(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overlays, path)
+	s.dirty = true
+}
+
+// ReadFile returns path's current content: the open buffer if the client
+// has one, otherwise whatever's on disk.
+func (s *Snapshot) ReadFile(path string) (string, error) {
+	s.mu.Lock()
+	if ov, ok := s.overlays[path]; ok {
+		s.mu.Unlock()
+		return ov.content, nil
+	}
+	s.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Invalidate marks the cached Project stale, forcing the next request that
+// needs one to rebuild it. didOpen/didChange already do this implicitly for
+// buffers the client has open; this is the counterpart for files it only
+// watches, reported via workspace/didChangeWatchedFiles.
+func (s *Snapshot) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty = true
+}
+
+// Project returns the workspace's analysis.Project, rebuilding it if any
+// file has changed since the last build.
+func (s *Snapshot) Project() (*analysis.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty && s.project != nil {
+		return s.project, nil
+	}
+
+	project, err := analysis.BuildProject(s.root)
+	if err != nil {
+		return nil, err
+	}
+	s.project = project
+	s.dirty = false
+	return s.project, nil
+}
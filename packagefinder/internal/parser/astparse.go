@@ -0,0 +1,416 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+// parseSourceAST parses source with a real ECMAScript parser
+// (github.com/tdewolff/parse/v2/js) and walks its AST to find import/
+// require/AMD-define sites, instead of matching statement shapes with
+// regexes. It reports ok=false for anything that parser's grammar doesn't
+// accept - a syntax error, or a TypeScript-only construct like `import
+// type` - so ParseSource can fall back to the mask+regex pipeline for those
+// files rather than returning a partial result.
+//
+// The AST itself carries no byte offsets, so each node's Start/End and
+// SpecifierStart/End are recovered by searching content for that node's own
+// rendered text (its keyword, then its quoted specifier) starting from a
+// cursor that only moves forward - js.Walk visits nodes in source order, so
+// the cursor never has to backtrack.
+func parseSourceAST(filePath, source string) (*File, bool) {
+	ast, err := js.Parse(parse.NewInputString(source), js.Options{})
+	if err != nil {
+		return nil, false
+	}
+
+	w := &astWalker{content: source, handledCalls: map[*js.CallExpr]bool{}}
+	js.Walk(w, ast)
+
+	return &File{
+		Path:           filePath,
+		Source:         source,
+		Imports:        w.imports,
+		Requires:       w.requires,
+		DynamicImports: w.dynamicImports,
+		AMDDefines:     w.amdDefines,
+	}, true
+}
+
+type astWalker struct {
+	content string
+	cursor  int
+
+	imports        []ImportDecl
+	requires       []RequireCall
+	dynamicImports []DynamicImport
+	amdDefines     []AMDDefine
+
+	// handledCalls marks require() CallExprs already turned into a
+	// RequireCall via their enclosing VarDecl (so the binding pattern -
+	// `const x = ...` vs `const { a, b } = ...` - is known), so the
+	// general *js.CallExpr case below doesn't also report them unbound.
+	handledCalls map[*js.CallExpr]bool
+}
+
+func (w *astWalker) Enter(n js.INode) js.IVisitor {
+	switch node := n.(type) {
+	case *js.ImportStmt:
+		w.visitImport(node)
+		return nil
+	case *js.VarDecl:
+		for _, be := range node.List {
+			w.visitVarBinding(node, be)
+		}
+	case *js.CallExpr:
+		if !w.handledCalls[node] {
+			w.visitCall(node)
+		}
+	}
+	return w
+}
+
+func (w *astWalker) Exit(js.INode) {}
+
+func (w *astWalker) visitImport(n *js.ImportStmt) {
+	quoted := string(n.Module)
+	stmtStart, stmtEnd, specStart, specEnd, ok := w.consumeStatement("import", quoted)
+	if !ok {
+		return
+	}
+
+	decl := ImportDecl{
+		Start:          stmtStart,
+		End:            stmtEnd,
+		Specifier:      unquote(quoted),
+		SpecifierStart: specStart,
+		SpecifierEnd:   specEnd,
+		IsSideEffect:   n.Default == nil && len(n.List) == 0,
+	}
+	if n.Default != nil {
+		decl.Default = string(n.Default)
+	}
+	for _, alias := range n.List {
+		if isNamespaceAlias(alias) {
+			decl.Namespace = string(alias.Binding)
+			continue
+		}
+		if alias.Binding == nil {
+			continue
+		}
+		name, importAlias := string(alias.Binding), ""
+		if alias.Name != nil {
+			name, importAlias = string(alias.Name), string(alias.Binding)
+		}
+		decl.Named = append(decl.Named, NamedImport{Name: name, Alias: importAlias})
+	}
+
+	w.imports = append(w.imports, decl)
+}
+
+// visitVarBinding handles a single `<pattern> = require(...)` binding
+// element, recording its local name(s) from the AST's own binding pattern -
+// a plain identifier, or an object pattern's (possibly renamed) entries -
+// rather than re-deriving them from text.
+func (w *astWalker) visitVarBinding(decl *js.VarDecl, be js.BindingElement) {
+	call, ok := be.Default.(*js.CallExpr)
+	if !ok || !isCalleeNamed(call, "require") {
+		return
+	}
+	quoted, ok := soleStringArg(call)
+	if !ok {
+		return
+	}
+	w.handledCalls[call] = true
+
+	stmtStart, stmtEnd, specStart, specEnd, ok := w.consumeStatement(string(decl.Bytes()), quoted)
+	if !ok {
+		// A later declarator in the same multi-declarator statement
+		// (`const a = require('x'), b = require('y')`) has no "const"/
+		// "let"/"var" keyword of its own ahead of it, so the search above
+		// fails once the cursor has moved past the first one; anchor on
+		// "require" itself instead of dropping the binding entirely.
+		stmtStart, stmtEnd, specStart, specEnd, ok = w.consumeStatement("require", quoted)
+		if !ok {
+			return
+		}
+	}
+
+	rc := RequireCall{
+		Start:          stmtStart,
+		End:            stmtEnd,
+		Specifier:      unquote(quoted),
+		SpecifierStart: specStart,
+		SpecifierEnd:   specEnd,
+	}
+	switch binding := be.Binding.(type) {
+	case *js.Var:
+		rc.Binding = string(binding.Name())
+	case *js.BindingObject:
+		for _, item := range binding.List {
+			if v, ok := item.Value.Binding.(*js.Var); ok {
+				rc.Destructured = append(rc.Destructured, string(v.Name()))
+			}
+		}
+	}
+	w.requires = append(w.requires, rc)
+}
+
+// visitCall handles a require()/import()/define() call that isn't the
+// right-hand side of a variable binding - a side-effect require, a dynamic
+// import() used as an expression, or an AMD define().
+func (w *astWalker) visitCall(call *js.CallExpr) {
+	if lit, ok := call.X.(*js.LiteralExpr); ok && lit.TokenType == js.ImportToken {
+		w.visitDynamicImport(call)
+		return
+	}
+	if isCalleeNamed(call, "require") {
+		w.visitBareRequire(call)
+		return
+	}
+	if isCalleeNamed(call, "define") {
+		w.visitAMDDefine(call)
+	}
+}
+
+func (w *astWalker) visitBareRequire(call *js.CallExpr) {
+	quoted, ok := soleStringArg(call)
+	if !ok {
+		return
+	}
+	stmtStart, stmtEnd, specStart, specEnd, ok := w.consumeStatement("require", quoted)
+	if !ok {
+		return
+	}
+	w.requires = append(w.requires, RequireCall{
+		Start:          stmtStart,
+		End:            stmtEnd,
+		Specifier:      unquote(quoted),
+		SpecifierStart: specStart,
+		SpecifierEnd:   specEnd,
+	})
+}
+
+func (w *astWalker) visitDynamicImport(call *js.CallExpr) {
+	quoted, ok := soleStringArg(call)
+	if !ok {
+		return
+	}
+	stmtStart, stmtEnd, specStart, specEnd, ok := w.consumeStatement("import", quoted)
+	if !ok {
+		return
+	}
+	w.dynamicImports = append(w.dynamicImports, DynamicImport{
+		Start:          stmtStart,
+		End:            stmtEnd,
+		Specifier:      unquote(quoted),
+		SpecifierStart: specStart,
+		SpecifierEnd:   specEnd,
+	})
+}
+
+// visitAMDDefine builds an AMDDefine from a `define(...)` call, reading its
+// module id, dependency array, and factory function straight from the AST's
+// own argument list rather than guessing at the shape from raw text; only
+// the byte spans of the dependency array and factory body fall back to a
+// text search, for the same reason every other offset in this file does.
+func (w *astWalker) visitAMDDefine(call *js.CallExpr) {
+	kwIdx := indexWord(w.content, "define", w.cursor)
+	if kwIdx < 0 {
+		return
+	}
+	cursor := kwIdx + len("define")
+
+	var depsArr *js.ArrayExpr
+	var factory *js.FuncDecl
+	for _, arg := range call.Args.List {
+		switch v := arg.Value.(type) {
+		case *js.LiteralExpr:
+			if v.TokenType == js.StringToken {
+				if idx := strings.Index(w.content[cursor:], string(v.Data)); idx >= 0 {
+					cursor += idx + len(v.Data)
+				}
+			}
+		case *js.ArrayExpr:
+			depsArr = v
+		case *js.FuncDecl:
+			factory = v
+		}
+	}
+
+	define := AMDDefine{Start: kwIdx, End: cursor}
+
+	if depsArr != nil {
+		if bracketOpen := strings.IndexByte(w.content[cursor:], '['); bracketOpen >= 0 {
+			bracketOpen += cursor
+			if depsEnd := matchDelim(w.content, bracketOpen, '[', ']'); depsEnd >= 0 {
+				define.Deps = depNamesFromArray(depsArr)
+				define.DepsStart, define.DepsEnd = bracketOpen+1, depsEnd
+				define.End = depsEnd + 1
+				cursor = define.End
+			}
+		}
+	}
+
+	if factory != nil {
+		if bodyStart, bodyEnd, ok := factoryBodySpan(w.content, cursor); ok {
+			define.Params = paramNames(factory.Params)
+			define.BodyStart, define.BodyEnd = bodyStart, bodyEnd
+			define.End = closeOfCall(w.content, bodyEnd+1)
+		}
+	}
+
+	w.cursor = define.End
+	w.amdDefines = append(w.amdDefines, define)
+}
+
+// factoryBodySpan locates the `{...}` body of the `function(...) {...}`
+// expression starting at or after from, returning the span inside the
+// braces. Matching the parameter list's parens first (rather than just
+// scanning for the next '{') means a destructured parameter like
+// `function({a, b}) {...}` doesn't fool this into treating the pattern's own
+// brace as the function body.
+func factoryBodySpan(content string, from int) (bodyStart, bodyEnd int, ok bool) {
+	fnIdx := indexWord(content, "function", from)
+	if fnIdx < 0 {
+		return 0, 0, false
+	}
+	parenOpen := strings.IndexByte(content[fnIdx:], '(')
+	if parenOpen < 0 {
+		return 0, 0, false
+	}
+	parenOpen += fnIdx
+	parenClose := matchDelim(content, parenOpen, '(', ')')
+	if parenClose < 0 {
+		return 0, 0, false
+	}
+	braceOpen := strings.IndexByte(content[parenClose:], '{')
+	if braceOpen < 0 {
+		return 0, 0, false
+	}
+	braceOpen += parenClose
+	braceClose := matchDelim(content, braceOpen, '{', '}')
+	if braceClose < 0 {
+		return 0, 0, false
+	}
+	return braceOpen + 1, braceClose, true
+}
+
+func depNamesFromArray(arr *js.ArrayExpr) []string {
+	var deps []string
+	for _, el := range arr.List {
+		if lit, ok := el.Value.(*js.LiteralExpr); ok && lit.TokenType == js.StringToken {
+			deps = append(deps, unquote(string(lit.Data)))
+		}
+	}
+	return deps
+}
+
+func paramNames(params js.Params) []string {
+	var names []string
+	for _, be := range params.List {
+		if v, ok := be.Binding.(*js.Var); ok {
+			names = append(names, string(v.Name()))
+		}
+	}
+	return names
+}
+
+func isCalleeNamed(call *js.CallExpr, name string) bool {
+	v, ok := call.X.(*js.Var)
+	return ok && string(v.Name()) == name
+}
+
+// soleStringArg returns a call's sole string-literal argument, as it
+// appears in source including its surrounding quote characters (needed to
+// locate the call in content).
+func soleStringArg(call *js.CallExpr) (quoted string, ok bool) {
+	if len(call.Args.List) != 1 {
+		return "", false
+	}
+	lit, ok := call.Args.List[0].Value.(*js.LiteralExpr)
+	if !ok || lit.TokenType != js.StringToken {
+		return "", false
+	}
+	return string(lit.Data), true
+}
+
+func isNamespaceAlias(alias js.Alias) bool {
+	return len(alias.Name) == 1 && alias.Name[0] == '*'
+}
+
+func unquote(quoted string) string {
+	if len(quoted) >= 2 {
+		return quoted[1 : len(quoted)-1]
+	}
+	return quoted
+}
+
+// consumeStatement locates the statement that starts at or after w.cursor
+// with the whole word keyword and contains quoted (a specifier complete
+// with its surrounding quotes), advances w.cursor past it, and returns its
+// [start, end) span - including a call's closing ")" and a trailing
+// semicolon, if present - plus the specifier's own span with the quotes
+// excluded.
+func (w *astWalker) consumeStatement(keyword, quoted string) (stmtStart, stmtEnd, specStart, specEnd int, ok bool) {
+	kwIdx := indexWord(w.content, keyword, w.cursor)
+	if kwIdx < 0 {
+		return 0, 0, 0, 0, false
+	}
+	relIdx := strings.Index(w.content[kwIdx:], quoted)
+	if relIdx < 0 {
+		return 0, 0, 0, 0, false
+	}
+	qStart := kwIdx + relIdx
+	qEnd := qStart + len(quoted)
+
+	stmtEnd = qEnd
+	// require(...)/import(...) leave a closing ")" right after the
+	// specifier (possibly after whitespace); an `import ... from '...'`
+	// declaration has none, so this is a no-op for that shape.
+	j := stmtEnd
+	for j < len(w.content) && isSpaceByte(w.content[j]) {
+		j++
+	}
+	if j < len(w.content) && w.content[j] == ')' {
+		stmtEnd = j + 1
+	}
+	if stmtEnd < len(w.content) && w.content[stmtEnd] == ';' {
+		stmtEnd++
+	}
+	w.cursor = stmtEnd
+	return kwIdx, stmtEnd, qStart + 1, qEnd - 1, true
+}
+
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// indexWord returns the index of the first whole-word occurrence of word in
+// s at or after from, or -1 if there is none.
+func indexWord(s, word string, from int) int {
+	for i := from; i <= len(s)-len(word); {
+		idx := strings.Index(s[i:], word)
+		if idx < 0 {
+			return -1
+		}
+		pos := i + idx
+		before := pos == 0 || !isIdentByte(s[pos-1])
+		afterPos := pos + len(word)
+		after := afterPos >= len(s) || !isIdentByte(s[afterPos])
+		if before && after {
+			return pos
+		}
+		i = pos + 1
+	}
+	return -1
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' ||
+		b >= '0' && b <= '9' ||
+		b >= 'a' && b <= 'z' ||
+		b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,104 @@
+package parser
+
+// mask returns a byte-for-byte copy of src with the contents of every
+// comment and template literal replaced by spaces (newlines are preserved,
+// so byte offsets computed against the mask still line up with src). The
+// statement-matching regexes in parse.go run against the mask, not src, so
+// text that only *looks* like an import/require inside a comment or a
+// template literal - e.g. “ `import ${x} from 'y'` “ - is never mistaken
+// for a real one. Plain single- and double-quoted strings are left
+// untouched, since that's exactly where a real specifier lives.
+func mask(src string) []byte {
+	out := []byte(src)
+	i := 0
+	for i < len(out) {
+		switch {
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			start := i
+			for i < len(out) && out[i] != '\n' {
+				i++
+			}
+			blank(out, start, i)
+
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			start := i
+			i += 2
+			for i+1 < len(out) && !(out[i] == '*' && out[i+1] == '/') {
+				i++
+			}
+			i = min(i+2, len(out))
+			blank(out, start, i)
+
+		case out[i] == '`':
+			start := i
+			i++
+			for i < len(out) && out[i] != '`' {
+				if out[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i = min(i+1, len(out))
+			blank(out, start, i)
+
+		case out[i] == '\'' || out[i] == '"':
+			quote := out[i]
+			i++
+			for i < len(out) && out[i] != quote {
+				if out[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i = min(i+1, len(out))
+
+		default:
+			i++
+		}
+	}
+	return out
+}
+
+func blank(b []byte, start, end int) {
+	for i := start; i < end; i++ {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+}
+
+// matchDelim returns the index of the delimiter that closes the one at
+// openIdx (s[openIdx] == open), skipping over nested open/close pairs and
+// quoted strings so a bracket or brace inside a string literal doesn't throw
+// off the depth count. It returns -1 if open is never closed.
+func matchDelim(s string, openIdx int, open, close byte) int {
+	depth := 0
+	for i := openIdx; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"':
+			i = skipString(s, i) - 1
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// skipString returns the index just past the quoted string starting at i
+// (s[i] is the opening quote), honoring backslash escapes.
+func skipString(s string, i int) int {
+	quote := s[i]
+	i++
+	for i < len(s) && s[i] != quote {
+		if s[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	return min(i+1, len(s))
+}
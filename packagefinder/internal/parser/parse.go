@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// importDeclRegex matches `import [type] <bindings> from '<specifier>'`.
+	// <bindings> is captured as raw text and classified by parseBindings,
+	// since which of the five legal shapes (default / namespace / named /
+	// default+namespace / default+named) it is can't be told apart by a
+	// single capture group alone. (?s) lets <bindings> span lines, so a
+	// multi-line named-import list parses the same as a single-line one.
+	importDeclRegex = regexp.MustCompile(`(?s)import\s+(type\s+)?([^;'"` + "`" + `]*?)\s+from\s+(['"])((?:[^'"\\]|\\.)*)['"]`)
+
+	// importSideEffectRegex matches a bindings-free `import '<specifier>'`.
+	importSideEffectRegex = regexp.MustCompile(`import\s+(['"])((?:[^'"\\]|\\.)*)['"]`)
+
+	requireCallRegex = regexp.MustCompile(`(?:(?:const|let|var)\s+(?:\{([^}]*)\}|(\w+))\s*=\s*)?require\s*\(\s*['"]((?:[^'"\\]|\\.)*)['"]\s*\)`)
+
+	dynamicImportRegex = regexp.MustCompile(`import\s*\(\s*['"]((?:[^'"\\]|\\.)*)['"]\s*\)`)
+
+	// amdDefineStartRegex finds the start of a dependency-array `define([`
+	// call; the array and the factory function that follows it are then
+	// delimiter-matched by hand (matchDelim), since a regex can't track
+	// nested brackets/braces in the factory body.
+	amdDefineStartRegex = regexp.MustCompile(`define\s*\(\s*\[`)
+	depNameRegex        = regexp.MustCompile(`['"]([^'"]+)['"]`)
+
+	// amdFactoryHeaderRegex matches the `, function(...params) {` that
+	// follows an AMD dependency array's closing `]`, anchored at its start
+	// (^) since it's matched against the remainder of masked right after
+	// that `]`.
+	amdFactoryHeaderRegex = regexp.MustCompile(`^\s*,\s*function\s*(?:\w+\s*)?\(([^()]*)\)\s*\{`)
+
+	namespaceOnlyRegex       = regexp.MustCompile(`^\*\s+as\s+(\w+)$`)
+	defaultAndNamespaceRegex = regexp.MustCompile(`(?s)^(\w+)\s*,\s*\*\s+as\s+(\w+)$`)
+	defaultAndNamedRegex     = regexp.MustCompile(`(?s)^(\w+)\s*,\s*\{(.*)\}$`)
+	namedOnlyRegex           = regexp.MustCompile(`(?s)^\{(.*)\}$`)
+	defaultOnlyRegex         = regexp.MustCompile(`^(\w+)$`)
+)
+
+// Parse reads filePath and parses its contents.
+func Parse(filePath string) (*File, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSource(filePath, string(data))
+}
+
+// ParseSource parses source without touching disk, for callers that already
+// have the file's content in memory (or want to parse a non-file buffer).
+//
+// It tries a real ECMAScript parser (parseSourceAST) first, since walking an
+// actual parse tree gets shapes regexes only approximate - a multi-line
+// named-import list, a specifier written with unusual whitespace - exactly
+// right. Content that parser's grammar rejects (a syntax error, or a
+// TypeScript-only construct like `import type`) falls back to the
+// mask+regex pipeline below, since a partial AST-based result for input the
+// parser couldn't make sense of is more likely to mislead than a
+// regex-only pass over the whole file.
+func ParseSource(filePath, source string) (*File, error) {
+	if f, ok := parseSourceAST(filePath, source); ok {
+		return f, nil
+	}
+
+	masked := string(mask(source))
+
+	f := &File{Path: filePath, Source: source}
+	f.Imports = parseImportDecls(masked)
+	f.Requires = parseRequireCalls(masked)
+	f.DynamicImports = parseDynamicImports(masked)
+	f.AMDDefines = parseAMDDefines(masked)
+	return f, nil
+}
+
+func parseImportDecls(masked string) []ImportDecl {
+	var decls []ImportDecl
+
+	// Bindings-free imports ("import 'side-effect-path'") don't have a
+	// "from" clause, so they're matched separately; a guard against
+	// re-matching a spot already covered by importDeclRegex keeps a single
+	// statement from being reported twice.
+	covered := make([]bool, len(masked)+1)
+
+	for _, m := range importDeclRegex.FindAllStringSubmatchIndex(masked, -1) {
+		if m == nil || m[8] < 0 || m[9] < m[8] {
+			continue
+		}
+		start, end := m[0], m[1]
+		for i := start; i < end && i < len(covered); i++ {
+			covered[i] = true
+		}
+
+		bindings := strings.TrimSpace(masked[m[4]:m[5]])
+		specStart, specEnd := m[8], m[9]
+
+		decl := ImportDecl{
+			Start:          start,
+			End:            end,
+			Specifier:      masked[specStart:specEnd],
+			SpecifierStart: specStart,
+			SpecifierEnd:   specEnd,
+			IsTypeOnly:     m[2] >= 0,
+		}
+		decl.Default, decl.Namespace, decl.Named = parseBindings(bindings)
+		decls = append(decls, decl)
+	}
+
+	for _, m := range importSideEffectRegex.FindAllStringSubmatchIndex(masked, -1) {
+		if m == nil || m[4] < 0 || m[5] < m[4] || covered[m[0]] {
+			continue
+		}
+		decls = append(decls, ImportDecl{
+			Start:          m[0],
+			End:            m[1],
+			Specifier:      masked[m[4]:m[5]],
+			SpecifierStart: m[4],
+			SpecifierEnd:   m[5],
+			IsSideEffect:   true,
+		})
+	}
+
+	return decls
+}
+
+// parseBindings classifies an import declaration's bindings clause into its
+// default/namespace/named parts. The five shapes are mutually exclusive and
+// each fully anchored, so trying them in this order - most specific
+// (default+X) before least (X alone) - never misclassifies one as another.
+func parseBindings(clause string) (defaultName, namespace string, named []NamedImport) {
+	if m := defaultAndNamespaceRegex.FindStringSubmatch(clause); m != nil {
+		return m[1], m[2], nil
+	}
+	if m := defaultAndNamedRegex.FindStringSubmatch(clause); m != nil {
+		return m[1], "", parseNamedClause(m[2])
+	}
+	if m := namespaceOnlyRegex.FindStringSubmatch(clause); m != nil {
+		return "", m[1], nil
+	}
+	if m := namedOnlyRegex.FindStringSubmatch(clause); m != nil {
+		return "", "", parseNamedClause(m[1])
+	}
+	if m := defaultOnlyRegex.FindStringSubmatch(clause); m != nil {
+		return m[1], "", nil
+	}
+	return "", "", nil
+}
+
+func parseNamedClause(inner string) []NamedImport {
+	var named []NamedImport
+	for _, part := range strings.Split(inner, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, alias := part, ""
+		if idx := strings.Index(part, " as "); idx >= 0 {
+			name = strings.TrimSpace(part[:idx])
+			alias = strings.TrimSpace(part[idx+len(" as "):])
+		}
+		named = append(named, NamedImport{Name: name, Alias: alias})
+	}
+	return named
+}
+
+func parseRequireCalls(masked string) []RequireCall {
+	var calls []RequireCall
+	for _, m := range requireCallRegex.FindAllStringSubmatchIndex(masked, -1) {
+		if m == nil || m[6] < 0 || m[7] < m[6] {
+			continue
+		}
+
+		call := RequireCall{
+			Start:          m[0],
+			End:            m[1],
+			Specifier:      masked[m[6]:m[7]],
+			SpecifierStart: m[6],
+			SpecifierEnd:   m[7],
+		}
+		if m[4] > 0 && m[5] > m[4] {
+			call.Binding = strings.TrimSpace(masked[m[4]:m[5]])
+		}
+		if m[2] > 0 && m[3] > m[2] {
+			for _, symbol := range strings.Split(masked[m[2]:m[3]], ",") {
+				symbol = strings.TrimSpace(symbol)
+				if idx := strings.Index(symbol, ":"); idx >= 0 {
+					symbol = strings.TrimSpace(symbol[idx+1:])
+				}
+				if symbol != "" {
+					call.Destructured = append(call.Destructured, symbol)
+				}
+			}
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+func parseDynamicImports(masked string) []DynamicImport {
+	var imports []DynamicImport
+	for _, m := range dynamicImportRegex.FindAllStringSubmatchIndex(masked, -1) {
+		if m == nil || m[2] < 0 || m[3] < m[2] {
+			continue
+		}
+		imports = append(imports, DynamicImport{
+			Start:          m[0],
+			End:            m[1],
+			Specifier:      masked[m[2]:m[3]],
+			SpecifierStart: m[2],
+			SpecifierEnd:   m[3],
+		})
+	}
+	return imports
+}
+
+func parseAMDDefines(masked string) []AMDDefine {
+	var defines []AMDDefine
+	for _, loc := range amdDefineStartRegex.FindAllStringIndex(masked, -1) {
+		start, bracketOpen := loc[0], loc[1]-1
+		depsEnd := matchDelim(masked, bracketOpen, '[', ']')
+		if depsEnd < 0 {
+			continue
+		}
+		depsStart := bracketOpen + 1
+
+		var deps []string
+		for _, dep := range depNameRegex.FindAllStringSubmatch(masked[depsStart:depsEnd], -1) {
+			if len(dep) > 1 {
+				deps = append(deps, dep[1])
+			}
+		}
+
+		define := AMDDefine{Start: start, End: depsEnd + 1, Deps: deps, DepsStart: depsStart, DepsEnd: depsEnd}
+		if m := amdFactoryHeaderRegex.FindStringSubmatchIndex(masked[depsEnd+1:]); m != nil {
+			define.Params = splitParamNames(masked[depsEnd+1+m[2] : depsEnd+1+m[3]])
+
+			braceOpen := depsEnd + m[1] // index of the header match's trailing '{'
+			if bodyEnd := matchDelim(masked, braceOpen, '{', '}'); bodyEnd > 0 {
+				define.BodyStart, define.BodyEnd = braceOpen+1, bodyEnd
+				define.End = closeOfCall(masked, bodyEnd+1)
+			}
+		}
+		defines = append(defines, define)
+	}
+	return defines
+}
+
+func splitParamNames(s string) []string {
+	var names []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			names = append(names, p)
+		}
+	}
+	return names
+}
+
+// closeOfCall returns the index just past the `)` (and, if present, a
+// trailing `;`) that closes a call expression, skipping whitespace from i.
+// If no `)` is found at i after skipping whitespace, i is returned as-is.
+func closeOfCall(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i++
+	}
+	if i < len(s) && s[i] == ')' {
+		i++
+	}
+	if i < len(s) && s[i] == ';' {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,102 @@
+// Package parser builds a syntax tree for a JS/TS file's import/require
+// sites. ParseSource parses with a real ECMAScript parser
+// (github.com/tdewolff/parse/v2/js) and walks its AST - so a multi-line
+// named-import list, a specifier written with unusual whitespace, or the
+// word "import" sitting inside a comment or template literal is handled
+// exactly the way the grammar says to, not by how well a regex approximates
+// it.
+//
+// That parser doesn't understand TypeScript-only syntax like `import type
+// { X }`, and source with an actual syntax error obviously can't be parsed
+// at all; ParseSource falls back to a token-aware mask+regex pipeline
+// (parse.go, lex.go) for those files. The mask step skips over string,
+// template literal, and comment contents as a single token each, so a
+// module specifier or keyword-looking text that merely *appears inside* one
+// of those is never mistaken for an actual import site - the same matching
+// the AST-based path gets from the grammar itself. The fallback recognizes
+// the same statement shapes ParseSource's callers need (ES6 imports,
+// CommonJS require, dynamic import(), AMD define()) but, being regex-based,
+// is the less precise of the two.
+package parser
+
+// NodeKind mirrors finder.NodeKind for the subset of import/require shapes
+// this package understands.
+type NodeKind string
+
+const (
+	KindImportDecl    NodeKind = "ImportDecl"
+	KindRequireCall   NodeKind = "RequireCall"
+	KindDynamicImport NodeKind = "DynamicImport"
+	KindAMDDefine     NodeKind = "AMDDefine"
+)
+
+// NamedImport is one `{ Name }` or `{ Name as Alias }` entry in an import's
+// named-bindings clause.
+type NamedImport struct {
+	Name  string
+	Alias string
+}
+
+// ImportDecl is a single `import ... from '...'` (or bare `import '...'`)
+// declaration.
+type ImportDecl struct {
+	Start, End int // byte offsets of the whole declaration, including the trailing semicolon if present
+
+	Specifier      string
+	SpecifierStart int // byte offset of the specifier text, inside its quotes
+	SpecifierEnd   int
+
+	Default      string // "" if there is no default import
+	Namespace    string // "" unless this is (or includes) `* as Namespace`
+	Named        []NamedImport
+	IsTypeOnly   bool // `import type { X } from '...'`
+	IsSideEffect bool // `import '...'` with no bindings at all
+}
+
+// RequireCall is a single `require('...')` call, optionally assigned to a
+// binding or destructured.
+type RequireCall struct {
+	Start, End     int
+	Specifier      string
+	SpecifierStart int
+	SpecifierEnd   int
+	Binding        string   // "" if not assigned to a plain identifier
+	Destructured   []string // names pulled from `const { a, b } = require(...)`
+}
+
+// DynamicImport is a single `import('...')` call expression.
+type DynamicImport struct {
+	Start, End     int
+	Specifier      string
+	SpecifierStart int
+	SpecifierEnd   int
+}
+
+// AMDDefine is a single `define([...deps], function(...params) { body })`
+// call; Deps and Params both preserve declaration order since AMD factories
+// address their dependencies positionally by parameter index. DepsStart/End
+// and BodyStart/End are only set (BodyEnd > 0) when the call has the
+// dependency-array-plus-function-expression shape ConvertImportStyle round-
+// trips; the dependency-free `define(factory)` and named-module
+// `define('id', [...], factory)` shapes are recognized for their Deps but
+// left out of that round-trip.
+type AMDDefine struct {
+	Start, End int
+
+	Deps               []string
+	DepsStart, DepsEnd int // byte span of the `[...]` dependency array, inside the brackets
+
+	Params             []string // factory function's positional parameter names
+	BodyStart, BodyEnd int      // byte span of the factory function's body, inside its braces
+}
+
+// File is the parsed result for one source file.
+type File struct {
+	Path   string
+	Source string
+
+	Imports        []ImportDecl
+	Requires       []RequireCall
+	DynamicImports []DynamicImport
+	AMDDefines     []AMDDefine
+}
@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSourceIgnoresImportLikeTextInCommentsAndTemplates(t *testing.T) {
+	src := "// import fake from 'nope';\n" +
+		"/* import alsoFake from 'nope'; */\n" +
+		"const s = `import ${x} from 'nope'`;\n" +
+		"import real from 'real-package';\n"
+
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Imports) != 1 {
+		t.Fatalf("expected 1 import, got %d: %+v", len(f.Imports), f.Imports)
+	}
+	if f.Imports[0].Specifier != "real-package" {
+		t.Errorf("expected specifier 'real-package', got %q", f.Imports[0].Specifier)
+	}
+}
+
+func TestParseSourceMultiLineNamedImportWithComments(t *testing.T) {
+	src := "import {\n" +
+		"  foo, // the foo thing\n" +
+		"  bar as baz,\n" +
+		"} from 'multi-line-pkg';\n"
+
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Imports) != 1 {
+		t.Fatalf("expected 1 import, got %d", len(f.Imports))
+	}
+	named := f.Imports[0].Named
+	if len(named) != 2 {
+		t.Fatalf("expected 2 named imports, got %d: %+v", len(named), named)
+	}
+	if named[0].Name != "foo" || named[1].Name != "bar" || named[1].Alias != "baz" {
+		t.Errorf("unexpected named bindings: %+v", named)
+	}
+}
+
+func TestParseSourceTypeOnlyImport(t *testing.T) {
+	f, err := ParseSource("f.ts", "import type { Foo } from 'types-pkg';\n")
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Imports) != 1 || !f.Imports[0].IsTypeOnly {
+		t.Fatalf("expected a single type-only import, got %+v", f.Imports)
+	}
+}
+
+func TestParseSourceDefaultAndNamespaceImport(t *testing.T) {
+	f, err := ParseSource("f.ts", "import Default, * as NS from 'mixed-pkg';\n")
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Imports) != 1 {
+		t.Fatalf("expected 1 import, got %d", len(f.Imports))
+	}
+	decl := f.Imports[0]
+	if decl.Default != "Default" || decl.Namespace != "NS" {
+		t.Errorf("expected Default=%q Namespace=%q, got Default=%q Namespace=%q", "Default", "NS", decl.Default, decl.Namespace)
+	}
+}
+
+func TestParseSourceDestructuredRequire(t *testing.T) {
+	f, err := ParseSource("f.ts", "const { a, b: renamed } = require('req-pkg');\n")
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Requires) != 1 {
+		t.Fatalf("expected 1 require, got %d", len(f.Requires))
+	}
+	destructured := f.Requires[0].Destructured
+	if len(destructured) != 2 || destructured[0] != "a" || destructured[1] != "renamed" {
+		t.Errorf("unexpected destructured bindings: %+v", destructured)
+	}
+}
+
+func TestAddNamedImportInsertsNewStatement(t *testing.T) {
+	src := "import React from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	edits := AddNamedImport(f, "lodash", "debounce")
+	if len(edits) != 1 {
+		t.Fatalf("expected 1 edit, got %d", len(edits))
+	}
+	out := ApplyEdits(src, edits)
+	if out != "import { debounce } from 'lodash';\nimport React from 'react';\n" {
+		t.Errorf("unexpected result: %q", out)
+	}
+}
+
+func TestAddNamedImportExtendsExistingClause(t *testing.T) {
+	src := "import { useState } from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	edits := AddNamedImport(f, "react", "useEffect")
+	out := ApplyEdits(src, edits)
+	if out != "import { useState, useEffect } from 'react';\n" {
+		t.Errorf("unexpected result: %q", out)
+	}
+}
+
+func TestAddNamedImportNoopWhenAlreadyPresent(t *testing.T) {
+	src := "import { useState } from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	if edits := AddNamedImport(f, "react", "useState"); edits != nil {
+		t.Errorf("expected no edits, got %+v", edits)
+	}
+}
+
+func TestAddDefaultImportInsertsNewStatement(t *testing.T) {
+	src := "import { useState } from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	edits := AddDefaultImport(f, "react", "React")
+	out := ApplyEdits(src, edits)
+	if out != "import React from 'react';\nimport { useState } from 'react';\n" {
+		t.Errorf("unexpected result: %q", out)
+	}
+}
+
+func TestAddDefaultImportNoopWhenAlreadyPresent(t *testing.T) {
+	src := "import React from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	if edits := AddDefaultImport(f, "react", "React"); edits != nil {
+		t.Errorf("expected no edits, got %+v", edits)
+	}
+}
+
+func TestRemoveImportDeletesStatementAndTrailingNewline(t *testing.T) {
+	src := "import React from 'react';\nimport { useState } from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	out := ApplyEdits(src, RemoveImport(f, f.Imports[0]))
+	if out != "import { useState } from 'react';\n" {
+		t.Errorf("unexpected result: %q", out)
+	}
+}
+
+func TestRewriteImportPathReplacesOnlySpecifier(t *testing.T) {
+	src := "import React from 'react';\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	out := ApplyEdits(src, RewriteImportPath(f.Imports[0], "preact/compat"))
+	if out != "import React from 'preact/compat';\n" {
+		t.Errorf("unexpected result: %q", out)
+	}
+}
+
+func TestParseSourceBareRequireNotAssignedToABinding(t *testing.T) {
+	f, err := ParseSource("f.ts", "sideEffects(require('polyfill'));\n")
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Requires) != 1 || f.Requires[0].Specifier != "polyfill" {
+		t.Fatalf("expected 1 require for 'polyfill', got %+v", f.Requires)
+	}
+	if f.Requires[0].Binding != "" || len(f.Requires[0].Destructured) != 0 {
+		t.Errorf("expected no binding for an unassigned require, got %+v", f.Requires[0])
+	}
+}
+
+func TestParseSourceMultiDeclaratorRequireStatement(t *testing.T) {
+	f, err := ParseSource("f.ts", "const a = require('first'), b = require('second');\n")
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.Requires) != 2 {
+		t.Fatalf("expected 2 requires, got %d: %+v", len(f.Requires), f.Requires)
+	}
+	if f.Requires[0].Specifier != "first" || f.Requires[0].Binding != "a" {
+		t.Errorf("unexpected first require: %+v", f.Requires[0])
+	}
+	if f.Requires[1].Specifier != "second" || f.Requires[1].Binding != "b" {
+		t.Errorf("unexpected second require: %+v", f.Requires[1])
+	}
+}
+
+func TestParseSourceAMDDefineWithDestructuredFactoryParam(t *testing.T) {
+	src := "define(['a'], function({ value }) { return value; });\n"
+	f, err := ParseSource("f.ts", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+	if len(f.AMDDefines) != 1 {
+		t.Fatalf("expected 1 AMD define, got %d: %+v", len(f.AMDDefines), f.AMDDefines)
+	}
+	define := f.AMDDefines[0]
+	if define.BodyEnd == 0 {
+		t.Fatalf("expected the factory body to be recovered, got %+v", define)
+	}
+	// The destructuring pattern's own "{ value }" must not be mistaken for
+	// the factory's body: the recovered body should be exactly the
+	// `return value;` statement, not include the parameter pattern.
+	body := src[define.BodyStart:define.BodyEnd]
+	if got := strings.TrimSpace(body); got != "return value;" {
+		t.Errorf("unexpected factory body: %q", body)
+	}
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Edit is a positional text replacement: replace File.Source[Start:End]
+// with NewText. Edits are independent of one another (none of the helpers
+// below ever returns overlapping edits for a single call), so callers can
+// apply a batch in any order as long as they apply byte offsets against the
+// original, unmodified source - the same contract go/ast/astutil's
+// analysis-driven rewrites follow.
+type Edit struct {
+	Start, End int
+	NewText    string
+}
+
+// AddNamedImport returns the edit(s) needed to make name available as a
+// named import from specifier. If an import from specifier already exists,
+// name is appended to its named-bindings list in place; otherwise a new
+// `import { name } from 'specifier';` statement is inserted at the top of
+// the file, before the first existing import (or at offset 0 if there are
+// none).
+func AddNamedImport(f *File, specifier, name string) []Edit {
+	for _, decl := range f.Imports {
+		if decl.Specifier != specifier {
+			continue
+		}
+		for _, existing := range decl.Named {
+			if existing.Name == name {
+				return nil
+			}
+		}
+		return []Edit{appendToNamedClause(f.Source, decl, name)}
+	}
+
+	insertAt := 0
+	if len(f.Imports) > 0 {
+		insertAt = f.Imports[0].Start
+	}
+	quote := quoteStyle(f.Source)
+	return []Edit{{
+		Start:   insertAt,
+		End:     insertAt,
+		NewText: fmt.Sprintf("import { %s } from %c%s%c;\n", name, quote, specifier, quote),
+	}}
+}
+
+// AddDefaultImport returns the edit(s) needed to make localName available
+// as the default import from specifier. If a default import from specifier
+// already exists under that exact name, no edit is needed; a different
+// existing default name is left alone (renaming it is RenameImportedSymbol's
+// job, not this one's) and a fresh `import localName from 'specifier';`
+// statement is inserted before the first existing import instead.
+func AddDefaultImport(f *File, specifier, localName string) []Edit {
+	for _, decl := range f.Imports {
+		if decl.Specifier == specifier && decl.Default == localName {
+			return nil
+		}
+	}
+
+	insertAt := 0
+	if len(f.Imports) > 0 {
+		insertAt = f.Imports[0].Start
+	}
+	quote := quoteStyle(f.Source)
+	return []Edit{{
+		Start:   insertAt,
+		End:     insertAt,
+		NewText: fmt.Sprintf("import %s from %c%s%c;\n", localName, quote, specifier, quote),
+	}}
+}
+
+// appendToNamedClause inserts ", name" just before the closing "}" of
+// decl's named-bindings clause. decl must already have a named clause
+// (callers only reach this path when decl.Named is non-empty, i.e. the
+// import already has a `{ ... }`); a decl with only a default or namespace
+// binding is a adding a *new* named clause entirely, which AddNamedImport
+// doesn't attempt - it falls back to inserting a fresh import statement.
+func appendToNamedClause(source string, decl ImportDecl, name string) Edit {
+	stmt := source[decl.Start:decl.End]
+	closeBrace := strings.LastIndexByte(stmt, '}')
+	if closeBrace < 0 {
+		// No named clause to extend (default- or namespace-only import);
+		// the caller should add a standalone import instead.
+		return Edit{Start: decl.Start, End: decl.Start, NewText: ""}
+	}
+	// Insert right after the last non-space character so "{ a }" becomes
+	// "{ a, b }" rather than "{ a , b }".
+	insertOffset := closeBrace
+	for insertOffset > 0 && (stmt[insertOffset-1] == ' ' || stmt[insertOffset-1] == '\t' || stmt[insertOffset-1] == '\n') {
+		insertOffset--
+	}
+	pos := decl.Start + insertOffset
+	return Edit{Start: pos, End: pos, NewText: ", " + name}
+}
+
+// RemoveImport returns the edit that deletes decl's entire declaration,
+// including one trailing newline if present, so the file doesn't end up
+// with a blank line in its place.
+func RemoveImport(f *File, decl ImportDecl) []Edit {
+	end := decl.End
+	for end < len(f.Source) && (f.Source[end] == ' ' || f.Source[end] == '\t') {
+		end++
+	}
+	if end < len(f.Source) && f.Source[end] == ';' {
+		end++
+	}
+	if end < len(f.Source) && f.Source[end] == '\n' {
+		end++
+	}
+	return []Edit{{Start: decl.Start, End: end, NewText: ""}}
+}
+
+// RewriteImportPath returns the edit that replaces decl's specifier text
+// (the part inside the quotes, not the quotes themselves) with newSpecifier.
+func RewriteImportPath(decl ImportDecl, newSpecifier string) []Edit {
+	return []Edit{{Start: decl.SpecifierStart, End: decl.SpecifierEnd, NewText: newSpecifier}}
+}
+
+// quoteStyle reports the quote character used by the file's first string
+// literal-bearing import, defaulting to single quotes to match this
+// project's own style when the file has no import to match against.
+func quoteStyle(source string) byte {
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\'' || source[i] == '"' {
+			return source[i]
+		}
+	}
+	return '\''
+}
+
+// ApplyEdits applies edits to source and returns the result. Edits must be
+// disjoint and are applied in descending Start order so earlier offsets
+// stay valid as later (higher-offset) edits are applied first.
+func ApplyEdits(source string, edits []Edit) string {
+	sorted := append([]Edit(nil), edits...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j].Start > sorted[j-1].Start; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+
+	result := source
+	for _, e := range sorted {
+		result = result[:e.Start] + e.NewText + result[e.End:]
+	}
+	return result
+}
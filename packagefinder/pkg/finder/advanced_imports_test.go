@@ -128,3 +128,91 @@ async function loadComponent() {
 		t.Errorf("Expected at least 1 Lodash import, got %d", len(lodashResults))
 	}
 }
+
+func TestDynamicImportConstantFolding(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-dynamic-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	testFilePath := filepath.Join(tempDir, "dynamic.js")
+	testFileContent := "const base = 'rea' + 'ct';\n" +
+		"const pkg = `${base}`;\n" +
+		"async function loadReact() {\n" +
+		"  const a = await import(pkg);\n" +
+		"  const b = await import(`${base}`);\n" +
+		"  const c = await System.import(pkg);\n" +
+		"  const url = await import.meta.resolve('react', import.meta.url);\n" +
+		"  return [a, b, c, url];\n" +
+		"}"
+
+	if err := os.WriteFile(testFilePath, []byte(testFileContent), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	results, err := findPackageInFile(testFilePath, testFileContent, "react")
+	if err != nil {
+		t.Fatalf("findPackageInFile failed: %v", err)
+	}
+
+	var dynamicCount, systemCount, importMapCount int
+	var sawParentURL bool
+	for _, result := range results {
+		switch result.ImportStyle {
+		case DynamicImport:
+			dynamicCount++
+		case SystemJS:
+			systemCount++
+		case ImportMaps:
+			importMapCount++
+			if result.ParentURL == "import.meta.url" {
+				sawParentURL = true
+			}
+		}
+	}
+
+	if dynamicCount < 2 {
+		t.Errorf("Expected at least 2 constant-folded dynamic imports (identifier and template literal), got %d", dynamicCount)
+	}
+	if systemCount < 1 {
+		t.Errorf("Expected System.import(pkg) to resolve via the folded constant, got %d", systemCount)
+	}
+	if importMapCount < 1 {
+		t.Errorf("Expected the two-argument import.meta.resolve form to be detected, got %d", importMapCount)
+	}
+	if !sawParentURL {
+		t.Errorf("Expected ParentURL to record the import.meta.resolve parentURL argument")
+	}
+}
+
+// TestDynamicImportConstantFoldingWithoutLiteralPackageName guards against
+// findPackageInFile's quickCheck literal-substring short-circuit skipping
+// the whole file before the constant-folding pass ever runs: this fixture
+// never spells "react" as a quoted string anywhere, only through a folded
+// concatenation.
+func TestDynamicImportConstantFoldingWithoutLiteralPackageName(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-dynamic-nolit-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	testFilePath := filepath.Join(tempDir, "dynamic.js")
+	testFileContent := "const pkg = 'rea' + 'ct';\n" +
+		"async function loadReact() {\n" +
+		"  return await import(pkg);\n" +
+		"}"
+
+	if err := os.WriteFile(testFilePath, []byte(testFileContent), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	results, err := findPackageInFile(testFilePath, testFileContent, "react")
+	if err != nil {
+		t.Fatalf("findPackageInFile failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ImportStyle != DynamicImport {
+		t.Fatalf("expected 1 DynamicImport result resolved via constant folding, got %+v", results)
+	}
+}
@@ -0,0 +1,423 @@
+// Package analysis builds a project-wide reference graph of import
+// bindings and their use sites, so a symbol's usages can be traced across
+// file boundaries - through re-exports, alias assignments, and barrel
+// files - rather than only within the file that imports it.
+//
+// The shape is bipartite: each file contributes Bindings (a local name
+// bound to a module + exported name) and Uses (a local name appearing
+// somewhere in the file's body). BuildProject resolves Bindings into a
+// reverse index keyed by (module, exported name) so Project.FindUsages
+// can start from a package + symbol and walk outward to every local name
+// and file that ultimately refers to it, the same way a re-export chain
+// is walked one hop at a time in pkg/finder's ProjectGraph - except here
+// the walk runs forward (package -> consumers) instead of backward
+// (consumer -> package).
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/packagefinder/pkg/finder"
+)
+
+// BindingKind classifies how a local name came to refer to a module
+// symbol.
+type BindingKind string
+
+const (
+	BindingDefault   BindingKind = "default"
+	BindingNamed     BindingKind = "named"
+	BindingNamespace BindingKind = "namespace"
+	BindingReexport  BindingKind = "reexport"
+	BindingAlias     BindingKind = "alias"
+)
+
+// Binding records one (local name -> module symbol) fact discovered in a
+// file.
+type Binding struct {
+	LocalName    string
+	SourceModule string
+	ExportedName string
+	Kind         BindingKind
+}
+
+// Use is a single appearance of LocalName in a file's body, outside of the
+// import/require statement that bound it.
+type Use struct {
+	LocalName string
+	Location  finder.Location
+}
+
+// Location is an alias of finder.Location so callers of this package don't
+// need to import pkg/finder just to name the type FindUsages returns.
+type Location = finder.Location
+
+type fileFacts struct {
+	path     string
+	bindings []Binding
+	uses     []Use
+}
+
+// Project is the built reference graph for one project root.
+type Project struct {
+	root  string
+	files map[string]*fileFacts
+}
+
+// BuildProject walks root, parses every JS/TS file's import/require sites
+// with finder.ASTParser (finder.RegexParser as its fallback for
+// non-grammar import styles and unparseable files), and records the
+// bindings and uses each file contributes. It does not resolve re-export or
+// alias chains itself - that happens lazily in FindUsages, so files can be
+// discovered in any order.
+func BuildProject(root string) (*Project, error) {
+	p := &Project{root: root, files: map[string]*fileFacts{}}
+	parser := finder.NewASTParser()
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isJSOrTSFile(filepath.Ext(path)) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		source := string(content)
+
+		nodes, err := parser.Parse(path, source)
+		if err != nil {
+			return nil
+		}
+
+		facts := &fileFacts{path: path}
+		facts.bindings = bindingsFromNodes(path, nodes)
+		facts.bindings = append(facts.bindings, aliasBindings(source, facts.bindings)...)
+		facts.uses = usesInSource(source, facts.bindings)
+		p.files[path] = facts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A relative import's SourceModule was resolved to an extension-less
+	// project path in bindingsFromNodes; now that every file is known,
+	// canonicalize it to the exact path it resolves to (matching the
+	// candidate extensions and index-file conventions Node uses) so it
+	// compares equal to the fileFacts.path key FindUsages walks toward.
+	for _, facts := range p.files {
+		for i, b := range facts.bindings {
+			if resolved := p.findFileForModule(b.SourceModule); resolved != "" {
+				facts.bindings[i].SourceModule = resolved
+			}
+		}
+	}
+
+	return p, nil
+}
+
+func isJSOrTSFile(ext string) bool {
+	switch ext {
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		return true
+	default:
+		return false
+	}
+}
+
+func bindingsFromNodes(file string, nodes []finder.ImportNode) []Binding {
+	var bindings []Binding
+	for _, node := range nodes {
+		module := resolveRelativeModule(file, node.ModuleName)
+		switch node.Kind {
+		case finder.NodeES6ImportAll:
+			bindings = append(bindings, Binding{
+				LocalName:    node.Namespace,
+				SourceModule: module,
+				ExportedName: "*",
+				Kind:         BindingNamespace,
+			})
+		case finder.NodeES6Import, finder.NodeCommonJSRequire:
+			for _, symbol := range node.Symbols {
+				if strings.HasPrefix(symbol, "* as ") {
+					bindings = append(bindings, Binding{
+						LocalName:    strings.TrimPrefix(symbol, "* as "),
+						SourceModule: module,
+						ExportedName: "*",
+						Kind:         BindingNamespace,
+					})
+					continue
+				}
+				// RegexParser's Symbols list doesn't distinguish a
+				// default import's local name from a named one, so both
+				// are tagged BindingNamed here; only the dedicated
+				// ES6ImportAll node above carries enough information to
+				// say BindingNamespace for certain.
+				bindings = append(bindings, Binding{
+					LocalName:    symbol,
+					SourceModule: module,
+					ExportedName: symbol,
+					Kind:         BindingNamed,
+				})
+			}
+		case finder.NodeExportNamed:
+			for _, symbol := range node.ReexportedAs {
+				bindings = append(bindings, Binding{
+					LocalName:    symbol,
+					SourceModule: module,
+					ExportedName: symbol,
+					Kind:         BindingReexport,
+				})
+			}
+		case finder.NodeExportAll:
+			bindings = append(bindings, Binding{
+				LocalName:    "*",
+				SourceModule: module,
+				ExportedName: "*",
+				Kind:         BindingReexport,
+			})
+		}
+	}
+	return bindings
+}
+
+// aliasAssignRegex matches a plain `const/let/var x = y;` assignment, the
+// shape a binding is re-pointed through before a chain like
+// `const rx = React; rx.Component` is usable.
+var aliasAssignRegex = regexp.MustCompile(`(?:const|let|var)\s+(\w+)\s*=\s*(\w+)\s*;`)
+
+// aliasBindings finds local names assigned directly from an existing
+// binding's local name and records them as identity edges (same module,
+// same exported name) so FindUsages can follow `rx` back to `React`
+// without rescanning the file's import statements.
+func aliasBindings(source string, existing []Binding) []Binding {
+	byLocalName := make(map[string]Binding, len(existing))
+	for _, b := range existing {
+		byLocalName[b.LocalName] = b
+	}
+
+	var aliases []Binding
+	for _, m := range aliasAssignRegex.FindAllStringSubmatch(source, -1) {
+		aliasName, sourceName := m[1], m[2]
+		origin, ok := byLocalName[sourceName]
+		if !ok || aliasName == sourceName {
+			continue
+		}
+		aliases = append(aliases, Binding{
+			LocalName:    aliasName,
+			SourceModule: origin.SourceModule,
+			ExportedName: origin.ExportedName,
+			Kind:         BindingAlias,
+		})
+	}
+	return aliases
+}
+
+// usesInSource finds every appearance of a bound local name in source,
+// skipping the import/require lines that introduced the binding in the
+// first place.
+func usesInSource(source string, bindings []Binding) []Use {
+	names := map[string]bool{}
+	for _, b := range bindings {
+		if b.LocalName != "" && b.LocalName != "*" {
+			names[b.LocalName] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	ordered := make([]string, 0, len(names))
+	for name := range names {
+		ordered = append(ordered, regexp.QuoteMeta(name))
+	}
+	useRegex := regexp.MustCompile(fmt.Sprintf(`\b(%s)\b`, strings.Join(ordered, "|")))
+
+	var uses []Use
+	for _, m := range useRegex.FindAllStringSubmatchIndex(source, -1) {
+		if m == nil || m[2] < 0 {
+			continue
+		}
+		start, end := m[2], m[3]
+		lineStart := strings.LastIndex(source[:start], "\n") + 1
+		lineEnd := strings.IndexByte(source[end:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(source)
+		} else {
+			lineEnd += end
+		}
+		line := source[lineStart:lineEnd]
+		if (strings.Contains(line, "import") && strings.Contains(line, "from")) ||
+			strings.Contains(line, "export") ||
+			strings.Contains(line, "require(") ||
+			aliasAssignRegex.MatchString(line) {
+			continue
+		}
+
+		lineNum, charPos := lineAndCharacter(source, start)
+		uses = append(uses, Use{
+			LocalName: source[start:end],
+			Location: finder.Location{
+				Line:      lineNum,
+				Character: charPos,
+				Context:   strings.TrimSpace(line),
+			},
+		})
+	}
+	return uses
+}
+
+func lineAndCharacter(content string, offset int) (int, int) {
+	line := 1
+	lastNewline := -1
+	for i := 0; i < offset && i < len(content); i++ {
+		if content[i] == '\n' {
+			line++
+			lastNewline = i
+		}
+	}
+	return line, offset - lastNewline - 1
+}
+
+// resolveRelativeModule turns a relative specifier into a project-rooted
+// path so a barrel file's re-exports can be looked up by the same key
+// other files use to import it; bare specifiers (package names) are
+// returned unchanged.
+func resolveRelativeModule(fromFile, specifier string) string {
+	if !strings.HasPrefix(specifier, ".") {
+		return specifier
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(fromFile), specifier))
+}
+
+func (p *Project) findFileForModule(module string) string {
+	exts := []string{"", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+	for _, ext := range exts {
+		if _, ok := p.files[module+ext]; ok {
+			return module + ext
+		}
+		indexed := filepath.Join(module, "index"+ext)
+		if _, ok := p.files[indexed]; ok {
+			return indexed
+		}
+	}
+	return ""
+}
+
+// UsageLocation is one FindUsagesWithFile result: a Location plus the file
+// it was found in, which Location alone doesn't carry.
+type UsageLocation struct {
+	File     string
+	Location Location
+}
+
+// FindUsages returns every location that ultimately refers to symbol as
+// exported by pkg, following re-export and alias chains through any number
+// of intermediate modules.
+func (p *Project) FindUsages(pkg, symbol string) []Location {
+	var locations []Location
+	for _, u := range p.FindUsagesWithFile(pkg, symbol) {
+		locations = append(locations, u.Location)
+	}
+	return locations
+}
+
+// FindUsagesWithFile is FindUsages, but additionally names the file each
+// usage was found in, which a caller presenting cross-file results (e.g.
+// an LSP textDocument/references handler) needs and a bare Location
+// doesn't have.
+func (p *Project) FindUsagesWithFile(pkg, symbol string) []UsageLocation {
+	type target struct{ module, symbol string }
+	visited := map[target]bool{}
+	queue := []target{{pkg, symbol}}
+
+	var locations []UsageLocation
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if visited[t] {
+			continue
+		}
+		visited[t] = true
+
+		for _, facts := range p.files {
+			for _, b := range facts.bindings {
+				if b.SourceModule != t.module {
+					continue
+				}
+				if b.ExportedName != t.symbol && b.ExportedName != "*" {
+					continue
+				}
+
+				for _, u := range facts.uses {
+					if u.LocalName == b.LocalName {
+						locations = append(locations, UsageLocation{File: facts.path, Location: u.Location})
+					}
+				}
+
+				// This file re-exports the symbol under its own path, so
+				// any file that imports *this* file for the same exported
+				// name should also be resolved.
+				if b.Kind == BindingReexport {
+					queue = append(queue, target{facts.path, b.ExportedName})
+				}
+			}
+		}
+	}
+
+	return locations
+}
+
+// Files returns every file path the project parsed, sorted for
+// deterministic iteration (the underlying map isn't ordered).
+func (p *Project) Files() []string {
+	files := make([]string, 0, len(p.files))
+	for file := range p.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// BindingsIn returns the bindings file contributed to the project, or nil
+// if file isn't part of it.
+func (p *Project) BindingsIn(file string) []Binding {
+	facts, ok := p.files[file]
+	if !ok {
+		return nil
+	}
+	return facts.bindings
+}
+
+// BindingFor returns the Binding that local name localName resolves to in
+// file, or ok=false if file isn't in the project or has no such binding.
+// This is the lookup an LSP "go to references" handler needs to turn a
+// cursor position's identifier into the (module, exported name) pair
+// FindUsagesWithFile expects.
+func (p *Project) BindingFor(file, localName string) (Binding, bool) {
+	facts, ok := p.files[file]
+	if !ok {
+		return Binding{}, false
+	}
+	for _, b := range facts.bindings {
+		if b.LocalName == localName {
+			return b, true
+		}
+	}
+	return Binding{}, false
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindUsagesThroughBarrelReexport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-analysis-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	indexPath := filepath.Join(tempDir, "index.ts")
+	if err := os.WriteFile(indexPath, []byte(`export { debounce } from 'lodash';`), 0644); err != nil {
+		t.Fatalf("Failed to write index.ts: %v", err)
+	}
+
+	consumerPath := filepath.Join(tempDir, "consumer.ts")
+	consumerSrc := "import { debounce } from './index';\n" +
+		"const run = debounce(fn, 10);\n"
+	if err := os.WriteFile(consumerPath, []byte(consumerSrc), 0644); err != nil {
+		t.Fatalf("Failed to write consumer.ts: %v", err)
+	}
+
+	project, err := BuildProject(tempDir)
+	if err != nil {
+		t.Fatalf("BuildProject failed: %v", err)
+	}
+
+	locations := project.FindUsages("lodash", "debounce")
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 usage, got %d: %+v", len(locations), locations)
+	}
+	if locations[0].Line != 2 {
+		t.Errorf("expected usage on line 2, got %d", locations[0].Line)
+	}
+}
+
+func TestFindUsagesFollowsAliasAssignment(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-analysis-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "app.ts")
+	src := "import React from 'react';\n" +
+		"const rx = React;\n" +
+		"rx.Component();\n"
+	if err := os.WriteFile(filePath, []byte(src), 0644); err != nil {
+		t.Fatalf("Failed to write app.ts: %v", err)
+	}
+
+	project, err := BuildProject(tempDir)
+	if err != nil {
+		t.Fatalf("BuildProject failed: %v", err)
+	}
+
+	locations := project.FindUsages("react", "React")
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 usage reached through the alias, got %d: %+v", len(locations), locations)
+	}
+	if locations[0].Context != "rx.Component();" {
+		t.Errorf("unexpected context: %q", locations[0].Context)
+	}
+}
+
+func TestFindUsagesReturnsNilForUnknownSymbol(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-analysis-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "app.ts")
+	if err := os.WriteFile(filePath, []byte(`import React from 'react';`), 0644); err != nil {
+		t.Fatalf("Failed to write app.ts: %v", err)
+	}
+
+	project, err := BuildProject(tempDir)
+	if err != nil {
+		t.Fatalf("BuildProject failed: %v", err)
+	}
+
+	if locations := project.FindUsages("react", "NoSuchExport"); locations != nil {
+		t.Errorf("expected no usages, got %+v", locations)
+	}
+}
@@ -0,0 +1,371 @@
+package finder
+
+import (
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+// ASTParser implements Parser on top of a real ECMAScript parser
+// (github.com/tdewolff/parse/v2/js) instead of regular expressions, for the
+// forms that parser's grammar actually covers: ES6 import/export
+// declarations, CommonJS require(), and dynamic import(). Walking a real
+// parse tree - rather than matching patterns against raw source - means a
+// multi-line named-import list, a specifier written as a template literal,
+// or the word "import" sitting inside a comment or a string are handled
+// exactly the way the grammar says to, not by how well a regex
+// approximates it.
+//
+// AMD define(), SystemJS/UMD registration, import maps, and bare global
+// variables aren't part of the ES grammar - there's no node to walk for
+// them - so ASTParser always also runs RegexParser and merges in its nodes
+// for those styles. RegexParser is also the sole result for content this
+// parser can't parse at all (a syntax error, or a dialect - Flow, some
+// decorator proposals - outside this grammar), since a partial AST-based
+// result for invalid input is more likely to mislead than a regex-only
+// fallback.
+type ASTParser struct {
+	fallback *RegexParser
+}
+
+// NewASTParser returns the AST-backed Parser, with RegexParser wired in as
+// its fallback for non-grammar import styles and content the real parser
+// can't handle.
+func NewASTParser() *ASTParser {
+	return &ASTParser{fallback: NewRegexParser()}
+}
+
+// grammarKinds are the NodeKinds ASTParser itself produces; RegexParser's
+// nodes of these kinds are dropped from the merge so a real import/require/
+// export site is never reported twice.
+var grammarKinds = map[NodeKind]bool{
+	NodeES6Import:       true,
+	NodeES6ImportAll:    true,
+	NodeCommonJSRequire: true,
+	NodeDynamicImport:   true,
+	NodeExportNamed:     true,
+	NodeExportAll:       true,
+}
+
+func (p *ASTParser) Parse(filePath, content string) ([]ImportNode, error) {
+	ast, err := js.Parse(parse.NewInputString(content), js.Options{})
+	if err != nil {
+		return p.fallback.Parse(filePath, content)
+	}
+
+	w := &astWalker{content: content, handledCalls: map[*js.CallExpr]bool{}}
+	js.Walk(w, ast)
+
+	regexNodes, _ := p.fallback.Parse(filePath, content)
+	for _, n := range regexNodes {
+		if !grammarKinds[n.Kind] {
+			w.nodes = append(w.nodes, n)
+		}
+	}
+	return w.nodes, nil
+}
+
+// astWalker builds ImportNodes while walking a js.AST. The AST itself
+// carries no byte offsets, so each node's Start/End/Statement are recovered
+// by searching content for that node's own rendered text - its quoted
+// module specifier, for import/export/require/dynamic-import sites -
+// starting from a cursor that only moves forward. Walk visits nodes in
+// source order, so the cursor never has to backtrack.
+type astWalker struct {
+	content string
+	cursor  int
+	nodes   []ImportNode
+
+	// handledCalls marks require()/import() CallExprs already turned into
+	// an ImportNode via their enclosing VarDecl (so the binding pattern -
+	// `const x = ...` vs `const { a, b } = ...` - is known), so the
+	// general *js.CallExpr case below doesn't also report them unbound.
+	handledCalls map[*js.CallExpr]bool
+}
+
+func (w *astWalker) Enter(n js.INode) js.IVisitor {
+	switch node := n.(type) {
+	case *js.ImportStmt:
+		w.visitImport(node)
+		return nil
+	case *js.ExportStmt:
+		if node.Module != nil {
+			w.visitExportFrom(node)
+		}
+		return nil
+	case *js.VarDecl:
+		for _, be := range node.List {
+			w.visitVarBinding(be)
+		}
+	case *js.CallExpr:
+		if !w.handledCalls[node] {
+			w.visitBareCall(node)
+		}
+	}
+	return w
+}
+
+func (w *astWalker) Exit(js.INode) {}
+
+func (w *astWalker) visitImport(n *js.ImportStmt) {
+	quoted := string(n.Module)
+	start, end, ok := w.consumeStatement("import", quoted)
+	if !ok {
+		return
+	}
+	moduleName := unquote(quoted)
+	statement := extractStatement(w.content, start, end)
+
+	if len(n.List) == 1 && n.Default == nil && isNamespaceAlias(n.List[0]) {
+		namespace := string(n.List[0].Binding)
+		w.nodes = append(w.nodes, ImportNode{
+			Kind:       NodeES6ImportAll,
+			Style:      ES6Import,
+			ModuleName: moduleName,
+			Statement:  statement,
+			Start:      start,
+			End:        end,
+			Namespace:  namespace,
+			Symbols:    []string{"* as " + namespace},
+		})
+		return
+	}
+
+	var symbols []string
+	if n.Default != nil {
+		symbols = append(symbols, string(n.Default))
+	}
+	for _, alias := range n.List {
+		if alias.Binding != nil {
+			symbols = append(symbols, string(alias.Binding))
+		}
+	}
+	if len(symbols) == 0 {
+		symbols = append(symbols, "(side-effect only)")
+	}
+
+	w.nodes = append(w.nodes, ImportNode{
+		Kind:       NodeES6Import,
+		Style:      ES6Import,
+		ModuleName: moduleName,
+		Statement:  statement,
+		Start:      start,
+		End:        end,
+		Symbols:    symbols,
+	})
+}
+
+func (w *astWalker) visitExportFrom(n *js.ExportStmt) {
+	quoted := string(n.Module)
+	start, end, ok := w.consumeStatement("export", quoted)
+	if !ok {
+		return
+	}
+	moduleName := unquote(quoted)
+	statement := extractStatement(w.content, start, end)
+
+	if len(n.List) == 1 && isStarAlias(n.List[0]) {
+		node := ImportNode{
+			Kind:       NodeExportAll,
+			Style:      ES6Import,
+			ModuleName: moduleName,
+			Statement:  statement,
+			Start:      start,
+			End:        end,
+		}
+		if n.List[0].Name != nil {
+			node.Namespace = string(n.List[0].Binding)
+		}
+		w.nodes = append(w.nodes, node)
+		return
+	}
+
+	var reexported []string
+	for _, alias := range n.List {
+		if alias.Binding != nil {
+			reexported = append(reexported, string(alias.Binding))
+		}
+	}
+	w.nodes = append(w.nodes, ImportNode{
+		Kind:         NodeExportNamed,
+		Style:        ES6Import,
+		ModuleName:   moduleName,
+		Statement:    statement,
+		Start:        start,
+		End:          end,
+		ReexportedAs: reexported,
+	})
+}
+
+// visitVarBinding handles a single `<pattern> = require(...)` binding
+// element, recording its local name(s) from the AST's own binding pattern -
+// a plain identifier, or an object pattern's (possibly renamed) entries -
+// rather than re-deriving them from text.
+func (w *astWalker) visitVarBinding(be js.BindingElement) {
+	call, ok := be.Default.(*js.CallExpr)
+	if !ok || !isCalleeNamed(call, "require") {
+		return
+	}
+	moduleName, quoted, ok := requireSpecifier(call)
+	if !ok {
+		return
+	}
+	w.handledCalls[call] = true
+
+	start, end, ok := w.consumeStatement("require", quoted)
+	if !ok {
+		return
+	}
+
+	var symbols []string
+	switch binding := be.Binding.(type) {
+	case *js.Var:
+		symbols = append(symbols, string(binding.Name()))
+	case *js.BindingObject:
+		for _, item := range binding.List {
+			if v, ok := item.Value.Binding.(*js.Var); ok {
+				symbols = append(symbols, string(v.Name()))
+			}
+		}
+	}
+
+	w.nodes = append(w.nodes, ImportNode{
+		Kind:       NodeCommonJSRequire,
+		Style:      CommonJS,
+		ModuleName: moduleName,
+		Statement:  extractStatement(w.content, start, end),
+		Start:      start,
+		End:        end,
+		Symbols:    symbols,
+	})
+}
+
+// visitBareCall handles a require()/import() call that isn't the right-hand
+// side of a variable binding - a side-effect require('./polyfill') or a
+// dynamic import() used as an expression.
+func (w *astWalker) visitBareCall(call *js.CallExpr) {
+	if lit, ok := call.X.(*js.LiteralExpr); ok && lit.TokenType == js.ImportToken {
+		moduleName, quoted, ok := requireSpecifier(call)
+		if !ok {
+			return
+		}
+		start, end, ok := w.consumeStatement("import", quoted)
+		if !ok {
+			return
+		}
+		w.nodes = append(w.nodes, ImportNode{
+			Kind:       NodeDynamicImport,
+			Style:      DynamicImport,
+			ModuleName: moduleName,
+			Statement:  extractStatement(w.content, start, end),
+			Start:      start,
+			End:        end,
+			IsDynamic:  true,
+		})
+		return
+	}
+
+	if isCalleeNamed(call, "require") {
+		moduleName, quoted, ok := requireSpecifier(call)
+		if !ok {
+			return
+		}
+		start, end, ok := w.consumeStatement("require", quoted)
+		if !ok {
+			return
+		}
+		w.nodes = append(w.nodes, ImportNode{
+			Kind:       NodeCommonJSRequire,
+			Style:      CommonJS,
+			ModuleName: moduleName,
+			Statement:  extractStatement(w.content, start, end),
+			Start:      start,
+			End:        end,
+		})
+	}
+}
+
+func isCalleeNamed(call *js.CallExpr, name string) bool {
+	v, ok := call.X.(*js.Var)
+	return ok && string(v.Name()) == name
+}
+
+// requireSpecifier returns a call's sole string-literal argument, both
+// unquoted (moduleName) and as it appears in source (quoted, including its
+// surrounding quote characters - needed to locate the call in content).
+func requireSpecifier(call *js.CallExpr) (moduleName, quoted string, ok bool) {
+	if len(call.Args.List) != 1 {
+		return "", "", false
+	}
+	lit, ok := call.Args.List[0].Value.(*js.LiteralExpr)
+	if !ok || lit.TokenType != js.StringToken {
+		return "", "", false
+	}
+	quoted = string(lit.Data)
+	return unquote(quoted), quoted, true
+}
+
+func isNamespaceAlias(alias js.Alias) bool {
+	return len(alias.Name) == 1 && alias.Name[0] == '*'
+}
+
+func isStarAlias(alias js.Alias) bool {
+	return (len(alias.Name) == 1 && alias.Name[0] == '*') ||
+		(alias.Name == nil && len(alias.Binding) == 1 && alias.Binding[0] == '*')
+}
+
+func unquote(quoted string) string {
+	if len(quoted) >= 2 {
+		return quoted[1 : len(quoted)-1]
+	}
+	return quoted
+}
+
+// consumeStatement locates the statement that starts at or after w.cursor
+// with the whole word keyword and contains quoted (a module specifier
+// complete with its surrounding quotes), advances w.cursor past it, and
+// returns its [start, end) span including a trailing semicolon if present.
+func (w *astWalker) consumeStatement(keyword, quoted string) (start, end int, ok bool) {
+	kwIdx := indexWord(w.content, keyword, w.cursor)
+	if kwIdx < 0 {
+		return 0, 0, false
+	}
+	relIdx := strings.Index(w.content[kwIdx:], quoted)
+	if relIdx < 0 {
+		return 0, 0, false
+	}
+	end = kwIdx + relIdx + len(quoted)
+	if end < len(w.content) && w.content[end] == ';' {
+		end++
+	}
+	w.cursor = end
+	return kwIdx, end, true
+}
+
+// indexWord returns the index of the first whole-word occurrence of word in
+// s at or after from, or -1 if there is none.
+func indexWord(s, word string, from int) int {
+	for i := from; i <= len(s)-len(word); {
+		idx := strings.Index(s[i:], word)
+		if idx < 0 {
+			return -1
+		}
+		pos := i + idx
+		before := pos == 0 || !isIdentByte(s[pos-1])
+		afterPos := pos + len(word)
+		after := afterPos >= len(s) || !isIdentByte(s[afterPos])
+		if before && after {
+			return pos
+		}
+		i = pos + 1
+	}
+	return -1
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' ||
+		b >= '0' && b <= '9' ||
+		b >= 'a' && b <= 'z' ||
+		b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,157 @@
+package finder
+
+import "testing"
+
+func nodeByModule(nodes []ImportNode, kind NodeKind, module string) *ImportNode {
+	for i := range nodes {
+		if nodes[i].Kind == kind && nodes[i].ModuleName == module {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+func TestASTParserHandlesMultiLineNamedImportWithComments(t *testing.T) {
+	content := `import {
+	useState,
+	// a comment mentioning import and require shouldn't confuse anything
+	useEffect as effect,
+} from 'react';
+`
+	nodes, err := NewASTParser().Parse("test.ts", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	node := nodeByModule(nodes, NodeES6Import, "react")
+	if node == nil {
+		t.Fatal("expected an ES6Import node for react")
+	}
+	if len(node.Symbols) != 2 || node.Symbols[0] != "useState" || node.Symbols[1] != "effect" {
+		t.Errorf("Symbols = %v; want [useState effect]", node.Symbols)
+	}
+}
+
+func TestASTParserIgnoresImportLookingTextInsideTemplateLiterals(t *testing.T) {
+	content := "const msg = `import foo from 'not-a-real-package'`;\nimport react from 'react';\n"
+
+	nodes, err := NewASTParser().Parse("test.ts", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if nodeByModule(nodes, NodeES6Import, "not-a-real-package") != nil {
+		t.Error("template literal content was mistaken for a real import")
+	}
+	if nodeByModule(nodes, NodeES6Import, "react") == nil {
+		t.Error("expected the real react import to still be found")
+	}
+}
+
+func TestASTParserIgnoresImportLookingTextInsideComments(t *testing.T) {
+	content := "// import fake from 'fake-package';\nimport react from 'react';\n"
+
+	nodes, err := NewASTParser().Parse("test.ts", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if nodeByModule(nodes, NodeES6Import, "fake-package") != nil {
+		t.Error("commented-out import was mistaken for a real one")
+	}
+	if nodeByModule(nodes, NodeES6Import, "react") == nil {
+		t.Error("expected the real react import to still be found")
+	}
+}
+
+func TestASTParserHandlesNamespaceImport(t *testing.T) {
+	nodes, err := NewASTParser().Parse("test.ts", "import * as utils from './utils';\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	node := nodeByModule(nodes, NodeES6ImportAll, "./utils")
+	if node == nil {
+		t.Fatal("expected an ES6ImportAll node")
+	}
+	if node.Namespace != "utils" {
+		t.Errorf("Namespace = %q; want utils", node.Namespace)
+	}
+}
+
+func TestASTParserHandlesDestructuredRequire(t *testing.T) {
+	nodes, err := NewASTParser().Parse("test.js", "const { debounce, throttle: slow } = require('lodash');\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	node := nodeByModule(nodes, NodeCommonJSRequire, "lodash")
+	if node == nil {
+		t.Fatal("expected a CommonJSRequire node for lodash")
+	}
+	if len(node.Symbols) != 2 || node.Symbols[0] != "debounce" || node.Symbols[1] != "slow" {
+		t.Errorf("Symbols = %v; want [debounce slow]", node.Symbols)
+	}
+}
+
+func TestASTParserHandlesDynamicImport(t *testing.T) {
+	nodes, err := NewASTParser().Parse("test.js", "async function load() {\n  const mod = await import('./lazy');\n}\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	node := nodeByModule(nodes, NodeDynamicImport, "./lazy")
+	if node == nil {
+		t.Fatal("expected a DynamicImport node for ./lazy")
+	}
+	if !node.IsDynamic {
+		t.Error("expected IsDynamic to be true")
+	}
+}
+
+func TestASTParserHandlesReexport(t *testing.T) {
+	nodes, err := NewASTParser().Parse("test.ts", "export { debounce as slow } from 'lodash';\nexport * from 'lodash-es';\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	named := nodeByModule(nodes, NodeExportNamed, "lodash")
+	if named == nil || len(named.ReexportedAs) != 1 || named.ReexportedAs[0] != "slow" {
+		t.Errorf("unexpected named re-export node: %+v", named)
+	}
+	if nodeByModule(nodes, NodeExportAll, "lodash-es") == nil {
+		t.Error("expected an ExportAll node for lodash-es")
+	}
+}
+
+func TestASTParserFallsBackToRegexForNonGrammarStyles(t *testing.T) {
+	content := "define(['react', 'lodash'], function(React, _) {});\n"
+
+	nodes, err := NewASTParser().Parse("test.js", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var sawAMD bool
+	for _, n := range nodes {
+		if n.Kind == NodeAMDDefine {
+			sawAMD = true
+		}
+	}
+	if !sawAMD {
+		t.Error("expected RegexParser's AMD detection to be merged in for a style the grammar doesn't cover")
+	}
+}
+
+func TestASTParserFallsBackEntirelyOnParseFailure(t *testing.T) {
+	content := "import { from 'react';\n" // deliberately malformed
+
+	nodes, err := NewASTParser().Parse("test.ts", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	regexNodes, err := NewRegexParser().Parse("test.ts", content)
+	if err != nil {
+		t.Fatalf("RegexParser.Parse failed: %v", err)
+	}
+	if len(nodes) != len(regexNodes) {
+		t.Errorf("expected ASTParser to fall back to RegexParser's exact node count (%d) on a parse error, got %d", len(regexNodes), len(nodes))
+	}
+}
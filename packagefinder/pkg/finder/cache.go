@@ -0,0 +1,262 @@
+package finder
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// toolVersion is folded into every cache key. Bump it whenever a change to
+// parsing or resolution logic could make an on-disk entry from an older
+// build stale without the file or resolver config itself having changed.
+const toolVersion = "1"
+
+// defaultCacheDir is where Pipeline stores its on-disk cache when the
+// caller doesn't override CacheDir.
+const defaultCacheDir = ".ts-diff-cache"
+
+// lruCapacity bounds FileCache's in-memory layer, so a single Run doesn't
+// re-read the same entry off disk when the same file content (and thus
+// cache key) recurs across several paths in a monorepo.
+const lruCapacity = 256
+
+// CacheMode controls how Pipeline's Parse stage uses its Cache.
+type CacheMode string
+
+const (
+	// CacheOn reads existing entries and writes new ones. The default.
+	CacheOn CacheMode = "on"
+	// CacheOff disables caching entirely: every file is re-parsed and
+	// nothing is read from or written to disk.
+	CacheOff CacheMode = "off"
+	// CacheRefresh skips reading existing entries (so stale results can
+	// never be reused) but still writes fresh ones, repopulating the cache
+	// for the next run.
+	CacheRefresh CacheMode = "refresh"
+)
+
+// cacheEntry is what's persisted per source file: the resolved usages, plus
+// the imported-symbol set findSymbolUsages needs for its pass, so a cache
+// hit can skip re-parsing the file entirely.
+type cacheEntry struct {
+	Usages          []PackageUsage `json:"usages"`
+	ImportedSymbols []string       `json:"importedSymbols"`
+}
+
+// Cache is what Pipeline's Parse stage uses to persist and reuse per-file
+// analysis results across runs. FileCache is the default on-disk
+// implementation; library users who want a different backing store (e.g.
+// Redis, BoltDB) can supply their own via Pipeline.Cache.
+type Cache interface {
+	// Key computes the cache key for a file's content, the package being
+	// searched for, and the project's resolver state.
+	Key(content []byte, packageName, resolverState string) string
+	// Get returns the cached entry for key, if present.
+	Get(key string) (*cacheEntry, bool)
+	// Put stores entry under key.
+	Put(key string, entry cacheEntry) error
+	// Record associates path with the cache key that currently describes
+	// it, so a later Prune can tell the entry is still live.
+	Record(path, key string)
+	// Prune drops entries whose source path no longer exists on disk.
+	Prune() error
+}
+
+// noopCache implements Cache as a set of no-ops, so Parse can treat
+// CacheOff as "use this cache" rather than special-casing every call site.
+type noopCache struct{}
+
+func (noopCache) Key(content []byte, packageName, resolverState string) string { return "" }
+func (noopCache) Get(key string) (*cacheEntry, bool)                           { return nil, false }
+func (noopCache) Put(key string, entry cacheEntry) error                      { return nil }
+func (noopCache) Record(path, key string)                                     {}
+func (noopCache) Prune() error                                                { return nil }
+
+// lruNode is one entry in FileCache's in-memory LRU layer.
+type lruNode struct {
+	key   string
+	value cacheEntry
+}
+
+// FileCache is a content-addressed, on-disk cache of per-file analysis
+// results. Entries are keyed by sha256(file bytes) || packageName ||
+// sha256(resolver config) || tool version, so searching for a different
+// package, or editing a tsconfig.json or import-map.json, invalidates the
+// relevant entries rather than needing a dependency graph. A manifest
+// tracks which file path last produced each key, so a later Prune can drop
+// entries for files that no longer exist. A bounded in-memory LRU sits in
+// front of the on-disk entries so a single run doesn't re-read the same
+// key from disk when several files share content.
+type FileCache struct {
+	dir string
+
+	mu       sync.Mutex
+	manifest map[string]string // absolute file path -> cache key
+	lru      *list.List
+	lruIndex map[string]*list.Element
+}
+
+// NewFileCache returns a FileCache rooted at dir, creating dir and loading
+// its manifest (if any) as needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	c := &FileCache{
+		dir:      dir,
+		manifest: map[string]string{},
+		lru:      list.New(),
+		lruIndex: map[string]*list.Element{},
+	}
+	if data, err := os.ReadFile(c.manifestPath()); err == nil {
+		json.Unmarshal(data, &c.manifest)
+	}
+	return c, nil
+}
+
+// DefaultGlobalCacheDir returns the directory the ts-diff CLI defaults
+// --cache-dir to when the user doesn't override it:
+// "$XDG_CACHE_HOME/packagefinder" (os.UserCacheDir honors XDG_CACHE_HOME on
+// Linux and the platform-appropriate equivalent elsewhere). Content-addressed
+// keys make it safe to share this directory across projects.
+func DefaultGlobalCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "packagefinder"), nil
+}
+
+// resolverStateKey hashes the project configuration a resolution result
+// depends on (tsconfig.json, import-map.json, package.json, when present),
+// so editing any of them invalidates every cache entry on the next run.
+func resolverStateKey(projectRoot string) string {
+	h := sha256.New()
+	for _, name := range []string{"tsconfig.json", "import-map.json", "package.json"} {
+		if data, err := os.ReadFile(filepath.Join(projectRoot, name)); err == nil {
+			h.Write([]byte(name))
+			h.Write(data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Key computes the cache key for a file's content, the package being
+// searched for, and the project's resolver state.
+func (c *FileCache) Key(content []byte, packageName, resolverState string) string {
+	fileSum := sha256.Sum256(content)
+	h := sha256.New()
+	h.Write(fileSum[:])
+	h.Write([]byte(packageName))
+	h.Write([]byte(resolverState))
+	h.Write([]byte(toolVersion))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *FileCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *FileCache) manifestPath() string {
+	return filepath.Join(c.dir, "manifest.json")
+}
+
+// lruGet returns key's entry from the in-memory layer, if present, and
+// marks it most-recently-used.
+func (c *FileCache) lruGet(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.lruIndex[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	entry := el.Value.(*lruNode).value
+	return &entry, true
+}
+
+// lruPut stores entry under key in the in-memory layer, evicting the
+// least-recently-used entry once lruCapacity is exceeded.
+func (c *FileCache) lruPut(key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.lruIndex[key]; ok {
+		el.Value.(*lruNode).value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.lruIndex[key] = c.lru.PushFront(&lruNode{key: key, value: entry})
+	if c.lru.Len() > lruCapacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.lruIndex, oldest.Value.(*lruNode).key)
+	}
+}
+
+// Get returns the cached entry for key, if present, checking the in-memory
+// LRU layer before touching disk.
+func (c *FileCache) Get(key string) (*cacheEntry, bool) {
+	if entry, ok := c.lruGet(key); ok {
+		return entry, true
+	}
+
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if json.Unmarshal(data, &entry) != nil {
+		return nil, false
+	}
+	c.lruPut(key, entry)
+	return &entry, true
+}
+
+// Put stores entry under key, both on disk and in the in-memory LRU layer.
+func (c *FileCache) Put(key string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.entryPath(key), data, 0644); err != nil {
+		return err
+	}
+	c.lruPut(key, entry)
+	return nil
+}
+
+// Record associates path with the cache key that currently describes it, so
+// a later Prune can tell the entry is still live.
+func (c *FileCache) Record(path, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifest[path] = key
+}
+
+// Prune drops manifest entries (and their backing cache files) whose source
+// path no longer exists on disk, then persists the updated manifest.
+func (c *FileCache) Prune() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path, key := range c.manifest {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			os.Remove(c.entryPath(key))
+			delete(c.manifest, path)
+		}
+	}
+
+	data, err := json.Marshal(c.manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.manifestPath(), data, 0644)
+}
@@ -0,0 +1,155 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCacheKeyDependsOnPackageName(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+
+	content := []byte("import React from 'react';\n")
+	reactKey := cache.Key(content, "react", "")
+	lodashKey := cache.Key(content, "lodash", "")
+
+	if reactKey == lodashKey {
+		t.Errorf("expected different cache keys for different package names, got the same key %q for both", reactKey)
+	}
+}
+
+func TestFileCacheGetServesFromLRUWithoutRereadingDisk(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+
+	key := cache.Key([]byte("content"), "react", "")
+	entry := cacheEntry{Usages: []PackageUsage{{FileName: "a.ts"}}}
+	if err := cache.Put(key, entry); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Remove the on-disk entry; a hit now can only come from the in-memory
+	// LRU layer Put populated.
+	if err := os.Remove(cache.entryPath(key)); err != nil {
+		t.Fatalf("failed to remove cache entry file: %v", err)
+	}
+
+	got, ok := cache.Get(key)
+	if !ok {
+		t.Fatalf("expected LRU hit after the on-disk entry was removed")
+	}
+	if len(got.Usages) != 1 || got.Usages[0].FileName != "a.ts" {
+		t.Errorf("unexpected cached entry: %+v", got)
+	}
+}
+
+func TestPipelineCacheModeOffSkipsDiskEntirely(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.ts"), "import React from 'react';\n")
+
+	p := NewPipeline(dir, "react")
+	p.CacheMode = CacheOff
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if _, err := os.ReadDir(filepath.Join(dir, defaultCacheDir)); err == nil {
+		t.Errorf("expected no cache directory to be created when CacheMode is CacheOff")
+	}
+}
+
+func TestPipelineCacheModeRefreshSkipsStaleReads(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "a.ts")
+	writeTestFile(t, filePath, "import React from 'react';\n")
+
+	if _, err := NewPipeline(dir, "react").Run(); err != nil {
+		t.Fatalf("first Run failed: %v", err)
+	}
+
+	p := NewPipeline(dir, "react")
+	p.CacheMode = CacheRefresh
+	results, err := p.Run()
+	if err != nil {
+		t.Fatalf("refresh Run failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+}
+
+func TestPipelineRewritesFileNameOnCacheHitAcrossSharedCacheDir(t *testing.T) {
+	sharedCache := t.TempDir()
+	projectA := t.TempDir()
+	projectB := t.TempDir()
+
+	const content = "import React from 'react';\n"
+	writeTestFile(t, filepath.Join(projectA, "a.ts"), content)
+	writeTestFile(t, filepath.Join(projectB, "b.ts"), content)
+
+	pa := NewPipeline(projectA, "react")
+	pa.CacheDir = sharedCache
+	resultsA, err := pa.Run()
+	if err != nil {
+		t.Fatalf("project A Run failed: %v", err)
+	}
+	if len(resultsA) != 1 || resultsA[0].FileName != filepath.Join(projectA, "a.ts") {
+		t.Fatalf("expected project A's result to name its own file, got %+v", resultsA)
+	}
+
+	// Identical content means project B hits the cache entry project A just
+	// wrote to the shared directory; the result must still name B's file.
+	pb := NewPipeline(projectB, "react")
+	pb.CacheDir = sharedCache
+	resultsB, err := pb.Run()
+	if err != nil {
+		t.Fatalf("project B Run failed: %v", err)
+	}
+	if len(resultsB) != 1 || resultsB[0].FileName != filepath.Join(projectB, "b.ts") {
+		t.Fatalf("expected project B's cached result to be rewritten to its own file, got %+v", resultsB)
+	}
+}
+
+// stubCache is a minimal Cache a library user might plug in instead of
+// FileCache, exercising Pipeline.Cache.
+type stubCache struct {
+	gets int
+	puts int
+}
+
+func (s *stubCache) Key(content []byte, packageName, resolverState string) string {
+	return packageName
+}
+func (s *stubCache) Get(key string) (*cacheEntry, bool) {
+	s.gets++
+	return nil, false
+}
+func (s *stubCache) Put(key string, entry cacheEntry) error {
+	s.puts++
+	return nil
+}
+func (s *stubCache) Record(path, key string) {}
+func (s *stubCache) Prune() error             { return nil }
+
+func TestPipelineUsesInjectedCache(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.ts"), "import React from 'react';\n")
+
+	p := NewPipeline(dir, "react")
+	stub := &stubCache{}
+	p.Cache = stub
+
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if stub.gets == 0 || stub.puts == 0 {
+		t.Errorf("expected the injected Cache to be used, got gets=%d puts=%d", stub.gets, stub.puts)
+	}
+}
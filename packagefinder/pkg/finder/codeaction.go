@@ -0,0 +1,297 @@
+package finder
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// TextEdit is a precise, byte-offset replacement within a single file.
+// Applying an action's Edits in any order against the file content they
+// were computed from is safe; re-running SuggestFixes against the result
+// produces no further edits, since the rewritten text no longer matches
+// the pattern that triggered it.
+type TextEdit struct {
+	File        string `json:"file"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Replacement string `json:"replacement"`
+}
+
+// CodeAction is a named, machine-applicable fix derived from a set of
+// PackageUsage results.
+type CodeAction struct {
+	Description string     `json:"description"`
+	Edits       []TextEdit `json:"edits"`
+}
+
+// FixPolicy selects which SuggestFixes rewrite rules run. Each field is
+// opt-in so callers only pay for the rewrites they ask for.
+type FixPolicy struct {
+	// RewriteSpecifiers maps a deprecated import specifier to its
+	// replacement, e.g. {"lodash/fp": "lodash-es"}.
+	RewriteSpecifiers map[string]string
+	// SplitDefaultImport maps a default import's local name to the named
+	// imports it should be replaced with, e.g. {"_": []string{"debounce"}}.
+	SplitDefaultImport map[string][]string
+	// CollapseDuplicateImports merges repeated `import ... from 'x'`
+	// statements targeting the same specifier in a file into one.
+	CollapseDuplicateImports bool
+	// UpgradeRequireToImport rewrites `const x = require('y')` to
+	// `import x from 'y'` for CommonJS usages with a single bound name.
+	UpgradeRequireToImport bool
+}
+
+var requireDeclRegex = regexp.MustCompile(`^(?:const|let|var)\s+(\w+)\s*=\s*require\s*\(\s*(['"])([^'"]+)(['"])\s*\)\s*;?$`)
+var exportFromSpecifierRegex = regexp.MustCompile(`from\s+['"]([^'"]+)['"]`)
+
+// fileCache memoizes file reads within a single SuggestFixes call; results
+// span many PackageUsage entries per file, so re-reading the same file for
+// every usage would be wasteful.
+type fileCache struct {
+	contents map[string]string
+	consumed map[string]int
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{contents: map[string]string{}, consumed: map[string]int{}}
+}
+
+func (c *fileCache) read(file string) (string, bool) {
+	if content, ok := c.contents[file]; ok {
+		return content, true
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+	content := string(data)
+	c.contents[file] = content
+	return content, true
+}
+
+// findStatement locates statement's next unconsumed occurrence in file so
+// repeated identical statements (e.g. two files requiring the same
+// specifier) each resolve to their own position rather than always the
+// first.
+func (c *fileCache) findStatement(file, statement string) (start, end int, ok bool) {
+	content, ok := c.read(file)
+	if !ok || statement == "" {
+		return 0, 0, false
+	}
+	from := c.consumed[file]
+	idx := strings.Index(content[from:], statement)
+	if idx < 0 {
+		idx = strings.Index(content, statement)
+		if idx < 0 {
+			return 0, 0, false
+		}
+		start = idx
+	} else {
+		start = from + idx
+	}
+	end = start + len(statement)
+	c.consumed[file] = end
+	return start, end, true
+}
+
+// SuggestFixes derives a list of CodeActions from results according to
+// policy. Edits are computed against the current on-disk content of each
+// result's FileName; a result whose ImportStatement can no longer be found
+// there (already rewritten, or the file has changed since results was
+// produced) is skipped rather than erroring, since results span many
+// unrelated files.
+func SuggestFixes(results []PackageUsage, policy FixPolicy) ([]CodeAction, error) {
+	cache := newFileCache()
+	actions := []CodeAction{}
+
+	if len(policy.RewriteSpecifiers) > 0 {
+		actions = append(actions, suggestSpecifierRewrites(results, policy.RewriteSpecifiers, cache)...)
+	}
+	if len(policy.SplitDefaultImport) > 0 {
+		actions = append(actions, suggestDefaultImportSplits(results, policy.SplitDefaultImport, cache)...)
+	}
+	if policy.UpgradeRequireToImport {
+		actions = append(actions, suggestRequireToImportUpgrades(results, cache)...)
+	}
+	if policy.CollapseDuplicateImports {
+		actions = append(actions, suggestDuplicateImportCollapses(results, cache)...)
+	}
+
+	return actions, nil
+}
+
+func suggestSpecifierRewrites(results []PackageUsage, rewrites map[string]string, cache *fileCache) []CodeAction {
+	actions := []CodeAction{}
+	for _, usage := range results {
+		if usage.ImportStyle != ES6Import && usage.ImportStyle != CommonJS {
+			continue
+		}
+		for oldSpecifier, newSpecifier := range rewrites {
+			if !strings.Contains(usage.ImportStatement, oldSpecifier) {
+				continue
+			}
+			start, end, ok := cache.findStatement(usage.FileName, usage.ImportStatement)
+			if !ok {
+				continue
+			}
+			quoted := regexp.MustCompile(fmt.Sprintf(`(['"])%s(['"])`, regexp.QuoteMeta(oldSpecifier)))
+			loc := quoted.FindStringIndex(usage.ImportStatement)
+			if loc == nil {
+				continue
+			}
+			specifierStart := start + loc[0] + 1
+			actions = append(actions, CodeAction{
+				Description: fmt.Sprintf("Rewrite deprecated import %q to %q in %s", oldSpecifier, newSpecifier, usage.FileName),
+				Edits: []TextEdit{{
+					File:        usage.FileName,
+					Start:       specifierStart,
+					End:         specifierStart + len(oldSpecifier),
+					Replacement: newSpecifier,
+				}},
+			})
+			_ = end
+		}
+	}
+	return actions
+}
+
+func suggestDefaultImportSplits(results []PackageUsage, splits map[string][]string, cache *fileCache) []CodeAction {
+	actions := []CodeAction{}
+	for _, usage := range results {
+		if usage.ImportStyle != ES6Import || len(usage.ImportedSymbols) == 0 {
+			continue
+		}
+		defaultName := usage.ImportedSymbols[0]
+		named, ok := splits[defaultName]
+		if !ok {
+			continue
+		}
+		defaultImportRegex := regexp.MustCompile(fmt.Sprintf(`import\s+%s\s+from`, regexp.QuoteMeta(defaultName)))
+		loc := defaultImportRegex.FindStringIndex(usage.ImportStatement)
+		if loc == nil {
+			continue
+		}
+		start, _, ok := cache.findStatement(usage.FileName, usage.ImportStatement)
+		if !ok {
+			continue
+		}
+		actions = append(actions, CodeAction{
+			Description: fmt.Sprintf("Split default import %q into named imports {%s} in %s", defaultName, strings.Join(named, ", "), usage.FileName),
+			Edits: []TextEdit{{
+				File:        usage.FileName,
+				Start:       start + loc[0],
+				End:         start + loc[1],
+				Replacement: fmt.Sprintf("import { %s } from", strings.Join(named, ", ")),
+			}},
+		})
+	}
+	return actions
+}
+
+func suggestRequireToImportUpgrades(results []PackageUsage, cache *fileCache) []CodeAction {
+	actions := []CodeAction{}
+	for _, usage := range results {
+		if usage.ImportStyle != CommonJS {
+			continue
+		}
+		match := requireDeclRegex.FindStringSubmatch(usage.ImportStatement)
+		if match == nil {
+			continue
+		}
+		start, end, ok := cache.findStatement(usage.FileName, usage.ImportStatement)
+		if !ok {
+			continue
+		}
+		localName, specifier := match[1], match[3]
+		actions = append(actions, CodeAction{
+			Description: fmt.Sprintf("Upgrade require(%q) to an ES6 import in %s", specifier, usage.FileName),
+			Edits: []TextEdit{{
+				File:        usage.FileName,
+				Start:       start,
+				End:         end,
+				Replacement: fmt.Sprintf("import %s from '%s';", localName, specifier),
+			}},
+		})
+	}
+	return actions
+}
+
+// suggestDuplicateImportCollapses groups ES6Import results by (file,
+// specifier) and, wherever a file has more than one distinct import
+// statement for the same specifier, emits an action that rewrites the
+// first occurrence into a single merged statement and deletes the rest.
+func suggestDuplicateImportCollapses(results []PackageUsage, cache *fileCache) []CodeAction {
+	type group struct {
+		specifier string
+		usages    []PackageUsage
+	}
+	groups := map[string]*group{}
+	order := []string{}
+	for _, usage := range results {
+		if usage.ImportStyle != ES6Import {
+			continue
+		}
+		m := exportFromSpecifierRegex.FindStringSubmatch(usage.ImportStatement)
+		if m == nil {
+			continue
+		}
+		key := usage.FileName + "\x00" + m[1]
+		g, ok := groups[key]
+		if !ok {
+			g = &group{specifier: m[1]}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.usages = append(g.usages, usage)
+	}
+
+	actions := []CodeAction{}
+	for _, key := range order {
+		g := groups[key]
+		distinctStatements := []string{}
+		seenStatement := map[string]bool{}
+		usageByStatement := map[string]PackageUsage{}
+		for _, usage := range g.usages {
+			if !seenStatement[usage.ImportStatement] {
+				seenStatement[usage.ImportStatement] = true
+				distinctStatements = append(distinctStatements, usage.ImportStatement)
+				usageByStatement[usage.ImportStatement] = usage
+			}
+		}
+		if len(distinctStatements) < 2 {
+			continue
+		}
+
+		allSymbols := []string{}
+		seenSymbol := map[string]bool{}
+		edits := []TextEdit{}
+		file := usageByStatement[distinctStatements[0]].FileName
+		for _, statement := range distinctStatements {
+			usage := usageByStatement[statement]
+			for _, symbol := range usage.ImportedSymbols {
+				if !seenSymbol[symbol] {
+					seenSymbol[symbol] = true
+					allSymbols = append(allSymbols, symbol)
+				}
+			}
+			start, end, ok := cache.findStatement(usage.FileName, statement)
+			if !ok {
+				continue
+			}
+			edits = append(edits, TextEdit{File: usage.FileName, Start: start, End: end})
+		}
+		if len(edits) < 2 {
+			continue
+		}
+		edits[0].Replacement = fmt.Sprintf("import { %s } from '%s';", strings.Join(allSymbols, ", "), g.specifier)
+
+		actions = append(actions, CodeAction{
+			Description: fmt.Sprintf("Collapse %d imports of %q into one statement in %s", len(distinctStatements), g.specifier, file),
+			Edits:       edits,
+		})
+	}
+
+	return actions
+}
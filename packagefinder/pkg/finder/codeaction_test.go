@@ -0,0 +1,93 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSuggestFixesUpgradeRequireToImport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-codeaction-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "app.js")
+	content := "const lodash = require('lodash');\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write app.js: %v", err)
+	}
+
+	results := []PackageUsage{{
+		FileName:        filePath,
+		ImportStatement: "const lodash = require('lodash');",
+		ImportStyle:     CommonJS,
+		ImportedSymbols: []string{"lodash"},
+	}}
+
+	actions, err := SuggestFixes(results, FixPolicy{UpgradeRequireToImport: true})
+	if err != nil {
+		t.Fatalf("SuggestFixes failed: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 action, got %d", len(actions))
+	}
+	if len(actions[0].Edits) != 1 {
+		t.Fatalf("expected 1 edit, got %d", len(actions[0].Edits))
+	}
+
+	edit := actions[0].Edits[0]
+	if got := content[edit.Start:edit.End]; got != results[0].ImportStatement {
+		t.Errorf("edit span = %q; want %q", got, results[0].ImportStatement)
+	}
+	if edit.Replacement != "import lodash from 'lodash';" {
+		t.Errorf("edit.Replacement = %q; want %q", edit.Replacement, "import lodash from 'lodash';")
+	}
+}
+
+func TestSuggestFixesCollapseDuplicateImports(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-codeaction-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "app.ts")
+	content := "import { debounce } from 'lodash';\nimport { throttle } from 'lodash';\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write app.ts: %v", err)
+	}
+
+	results := []PackageUsage{
+		{
+			FileName:        filePath,
+			ImportStatement: "import { debounce } from 'lodash';",
+			ImportStyle:     ES6Import,
+			ImportedSymbols: []string{"debounce"},
+		},
+		{
+			FileName:        filePath,
+			ImportStatement: "import { throttle } from 'lodash';",
+			ImportStyle:     ES6Import,
+			ImportedSymbols: []string{"throttle"},
+		},
+	}
+
+	actions, err := SuggestFixes(results, FixPolicy{CollapseDuplicateImports: true})
+	if err != nil {
+		t.Fatalf("SuggestFixes failed: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 action, got %d", len(actions))
+	}
+	if len(actions[0].Edits) != 2 {
+		t.Fatalf("expected 2 edits, got %d", len(actions[0].Edits))
+	}
+	if actions[0].Edits[0].Replacement == "" {
+		t.Error("expected the first edit to carry the merged replacement statement")
+	}
+	if actions[0].Edits[1].Replacement != "" {
+		t.Error("expected the second edit to be a pure deletion")
+	}
+}
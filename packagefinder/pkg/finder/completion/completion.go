@@ -0,0 +1,387 @@
+// Package completion suggests an import statement for an identifier a
+// user has typed but not yet imported, the same job gopls' unimported
+// completions do for Go: find where the symbol is actually defined, rank
+// the candidates, and hand back a ready-to-apply edit rather than making
+// the caller re-parse anything.
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/packagefinder/pkg/finder"
+	"github.com/user/packagefinder/pkg/finder/noderesolver"
+)
+
+// Ranking tiers, modeled on gopls' unimported-completions ordering:
+// already-imported packages outrank ones merely used elsewhere in the
+// workspace, which outrank an arbitrary node_modules entry nobody in the
+// project has imported yet. workspaceFile sits between the two workspace
+// tiers since a project-local file is usually more relevant to suggest
+// than a third-party package, even before anything else imports it.
+const (
+	scoreAlreadyImported   = 40
+	scoreImportedElsewhere = 30
+	scoreWorkspaceFile     = 20
+	scoreNodeModules       = 10
+)
+
+// ImportSuggestion is one candidate fix for an undefined identifier: the
+// package or file it's exported from, the edit that imports it, and a
+// score an editor can use to order multiple suggestions.
+type ImportSuggestion struct {
+	PackageName string
+	SymbolName  string
+	ImportStyle finder.ImportStyle
+	Edit        finder.TextEdit
+	Score       int
+}
+
+// SuggestImportsFor returns ranked import suggestions for ident, an
+// identifier typed in file with no corresponding import. It scans the
+// workspace containing file and, if present, its node_modules, for a
+// symbol named ident that could be imported; callers that need to know
+// *why* none were found (e.g. to distinguish "nothing exports this" from
+// an I/O error) should fall back to finder.NewASTParser directly - this
+// entry point returns nil in both cases so an editor plugin can treat
+// "no suggestions" uniformly.
+func SuggestImportsFor(file string, ident string) []ImportSuggestion {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	source := string(content)
+
+	root := findProjectRoot(filepath.Dir(file))
+	parser := finder.NewASTParser()
+	fileNodes, err := parser.Parse(file, source)
+	if err != nil {
+		return nil
+	}
+
+	importedHere := map[string]bool{}
+	for _, node := range fileNodes {
+		if node.ModuleName != "" {
+			importedHere[node.ModuleName] = true
+		}
+	}
+	importedElsewhere := scanWorkspaceImports(root, file, parser)
+
+	candidates := append(
+		findWorkspaceExporters(root, file, ident),
+		findNodeModulesExporters(root, ident)...,
+	)
+
+	style := dominantImportStyle(fileNodes)
+	insertAt := 0
+	if len(fileNodes) > 0 {
+		insertAt = fileNodes[0].Start
+	}
+
+	bySpecifier := map[string]ImportSuggestion{}
+	for _, c := range candidates {
+		score := scoreNodeModules
+		switch {
+		case importedHere[c.specifier]:
+			score = scoreAlreadyImported
+		case importedElsewhere[c.specifier]:
+			score = scoreImportedElsewhere
+		case c.fromWorkspace:
+			score = scoreWorkspaceFile
+		}
+
+		// Prefer the highest-scoring way to reach the same specifier
+		// (e.g. a bare package name found both in node_modules and
+		// imported elsewhere in the workspace) over adding it twice.
+		if existing, ok := bySpecifier[c.specifier]; ok && existing.Score >= score {
+			continue
+		}
+
+		bySpecifier[c.specifier] = ImportSuggestion{
+			PackageName: c.specifier,
+			SymbolName:  ident,
+			ImportStyle: style,
+			Edit:        insertEdit(file, style, c.specifier, ident, insertAt),
+			Score:       score,
+		}
+	}
+
+	if len(bySpecifier) == 0 {
+		return nil
+	}
+
+	suggestions := make([]ImportSuggestion, 0, len(bySpecifier))
+	for _, s := range bySpecifier {
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].PackageName < suggestions[j].PackageName
+	})
+	return suggestions
+}
+
+// dominantImportStyle reports the most common ImportStyle among nodes, or
+// ES6Import if the file has no imports to go by - a reasonable default
+// for new TypeScript/ESM files.
+func dominantImportStyle(nodes []finder.ImportNode) finder.ImportStyle {
+	counts := map[finder.ImportStyle]int{}
+	for _, node := range nodes {
+		counts[node.Style]++
+	}
+
+	best := finder.ES6Import
+	bestCount := 0
+	for style, count := range counts {
+		if count > bestCount {
+			best, bestCount = style, count
+		}
+	}
+	return best
+}
+
+// insertEdit renders the import statement for specifier/ident in style and
+// returns the TextEdit that inserts it at insertAt (the start of the
+// file's first existing import, or offset 0 if it has none).
+//
+// Only ES6Import and CommonJS have a single-line equivalent that doesn't
+// depend on surrounding code; an AMD file's dependency list lives in its
+// `define([...], factory)` call's own statement; rewriting the full
+// factory parameter list for a new dependency is a codemod, not a
+// completion insertion, so AMD (and any other style) falls back to the
+// ES6 form, which is always valid to add alongside an AMD module's
+// existing requires.
+func insertEdit(file string, style finder.ImportStyle, specifier, ident string, insertAt int) finder.TextEdit {
+	var text string
+	switch style {
+	case finder.CommonJS:
+		text = "const { " + ident + " } = require('" + specifier + "');\n"
+	default:
+		text = "import { " + ident + " } from '" + specifier + "';\n"
+	}
+	return finder.TextEdit{File: file, Start: insertAt, End: insertAt, Replacement: text}
+}
+
+type exportCandidate struct {
+	specifier     string
+	fromWorkspace bool
+}
+
+var (
+	exportDeclRegex     = regexp.MustCompile(`export\s+(?:const|let|var|function\*?|class)\s+(\w+)`)
+	exportDefaultRegex  = regexp.MustCompile(`export\s+default\s+(?:async\s+function\*?|function\*?|class)?\s*(\w+)`)
+	exportBraceRegex    = regexp.MustCompile(`export\s*\{([^}]*)\}`)
+	commonJSExportRegex = regexp.MustCompile(`(?:module\.)?exports\.(\w+)\s*=`)
+)
+
+// isJSOrTSFile reports whether ext names a file this package will scan for
+// export declarations.
+func isJSOrTSFile(ext string) bool {
+	switch ext {
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		return true
+	default:
+		return false
+	}
+}
+
+// fileExportsSymbol reports whether source contains a top-level export
+// declaration, default export, re-export brace clause, or CommonJS
+// exports assignment named ident.
+func fileExportsSymbol(source, ident string) bool {
+	for _, m := range exportDeclRegex.FindAllStringSubmatch(source, -1) {
+		if m[1] == ident {
+			return true
+		}
+	}
+	if m := exportDefaultRegex.FindStringSubmatch(source); m != nil && m[1] == ident {
+		return true
+	}
+	for _, m := range exportBraceRegex.FindAllStringSubmatch(source, -1) {
+		for _, name := range strings.Split(m[1], ",") {
+			name = strings.TrimSpace(name)
+			if idx := strings.Index(name, " as "); idx >= 0 {
+				name = strings.TrimSpace(name[idx+len(" as "):])
+			}
+			if name == ident {
+				return true
+			}
+		}
+	}
+	for _, m := range commonJSExportRegex.FindAllStringSubmatch(source, -1) {
+		if m[1] == ident {
+			return true
+		}
+	}
+	return false
+}
+
+// findWorkspaceExporters walks root (skipping node_modules/.git and the
+// file completion was requested for) looking for project files that
+// export ident, returning each as a relative specifier from file's
+// directory.
+func findWorkspaceExporters(root, file, ident string) []exportCandidate {
+	if root == "" {
+		return nil
+	}
+
+	var candidates []exportCandidate
+	fromDir := filepath.Dir(file)
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == file || !isJSOrTSFile(filepath.Ext(path)) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if !fileExportsSymbol(string(content), ident) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(fromDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = strings.TrimSuffix(rel, filepath.Ext(rel))
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+		candidates = append(candidates, exportCandidate{specifier: rel, fromWorkspace: true})
+		return nil
+	})
+
+	return candidates
+}
+
+// findNodeModulesExporters looks at each top-level package under
+// root/node_modules, resolves its entry file via noderesolver, and checks
+// whether that entry file exports ident.
+func findNodeModulesExporters(root, ident string) []exportCandidate {
+	if root == "" {
+		return nil
+	}
+	nodeModules := filepath.Join(root, "node_modules")
+	entries, err := os.ReadDir(nodeModules)
+	if err != nil {
+		return nil
+	}
+
+	var candidates []exportCandidate
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		packageNames := []string{entry.Name()}
+		// A scope directory (e.g. "@types") holds packages one level
+		// deeper, named "@scope/pkg".
+		if strings.HasPrefix(entry.Name(), "@") {
+			scoped, err := os.ReadDir(filepath.Join(nodeModules, entry.Name()))
+			if err != nil {
+				continue
+			}
+			packageNames = nil
+			for _, s := range scoped {
+				if s.IsDir() {
+					packageNames = append(packageNames, entry.Name()+"/"+s.Name())
+				}
+			}
+		}
+
+		resolver := noderesolver.NewResolver(root)
+		for _, pkgName := range packageNames {
+			resolution, err := resolver.Resolve(pkgName, noderesolver.PlatformNode)
+			if err != nil || resolution == nil || resolution.EntryPath == "" {
+				continue
+			}
+
+			content, err := os.ReadFile(resolution.EntryPath)
+			if err != nil || !fileExportsSymbol(string(content), ident) {
+				continue
+			}
+			candidates = append(candidates, exportCandidate{specifier: pkgName})
+		}
+	}
+	return candidates
+}
+
+// scanWorkspaceImports returns the set of bare specifiers imported by any
+// file in root other than file itself, for the "imported elsewhere in the
+// workspace" ranking tier.
+func scanWorkspaceImports(root, file string, parser finder.Parser) map[string]bool {
+	imported := map[string]bool{}
+	if root == "" {
+		return imported
+	}
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == file || !isJSOrTSFile(filepath.Ext(path)) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		nodes, err := parser.Parse(path, string(content))
+		if err != nil {
+			return nil
+		}
+		for _, node := range nodes {
+			if node.ModuleName != "" && !strings.HasPrefix(node.ModuleName, ".") {
+				imported[node.ModuleName] = true
+			}
+		}
+		return nil
+	})
+
+	return imported
+}
+
+// findProjectRoot walks up from dir looking for the nearest package.json
+// or node_modules directory, the same convention NewTSConfigResolver and
+// NewImportMapResolver use for their own nearest-ancestor lookups. It
+// returns "" rather than an error when nothing is found, so callers can
+// treat "no project root" as "nothing to scan" instead of a failure.
+func findProjectRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "package.json")); err == nil {
+			return dir
+		}
+		if info, err := os.Stat(filepath.Join(dir, "node_modules")); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
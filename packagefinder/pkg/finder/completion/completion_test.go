@@ -0,0 +1,99 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSuggestImportsForRanksWorkspaceOverNodeModules(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-completion-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"name":"app"}`), 0644); err != nil {
+		t.Fatalf("failed to write package.json: %v", err)
+	}
+
+	utilsPath := filepath.Join(tempDir, "utils.ts")
+	if err := os.WriteFile(utilsPath, []byte("export function formatDate(d) { return d; }\n"), 0644); err != nil {
+		t.Fatalf("failed to write utils.ts: %v", err)
+	}
+
+	nodeModulesPkg := filepath.Join(tempDir, "node_modules", "date-fns")
+	if err := os.MkdirAll(nodeModulesPkg, 0755); err != nil {
+		t.Fatalf("failed to create node_modules pkg: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(nodeModulesPkg, "package.json"), []byte(`{"name":"date-fns","main":"index.js"}`), 0644); err != nil {
+		t.Fatalf("failed to write package.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(nodeModulesPkg, "index.js"), []byte("exports.formatDate = function() {};\n"), 0644); err != nil {
+		t.Fatalf("failed to write index.js: %v", err)
+	}
+
+	appPath := filepath.Join(tempDir, "app.ts")
+	if err := os.WriteFile(appPath, []byte("formatDate(new Date());\n"), 0644); err != nil {
+		t.Fatalf("failed to write app.ts: %v", err)
+	}
+
+	suggestions := SuggestImportsFor(appPath, "formatDate")
+	if len(suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %d: %+v", len(suggestions), suggestions)
+	}
+	if suggestions[0].PackageName != "./utils" {
+		t.Errorf("expected the workspace file to rank first, got %q", suggestions[0].PackageName)
+	}
+	if suggestions[0].Score <= suggestions[1].Score {
+		t.Errorf("expected workspace suggestion to outscore node_modules: %+v", suggestions)
+	}
+}
+
+func TestSuggestImportsForUsesDominantStyle(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-completion-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"name":"app"}`), 0644); err != nil {
+		t.Fatalf("failed to write package.json: %v", err)
+	}
+
+	utilsPath := filepath.Join(tempDir, "utils.ts")
+	if err := os.WriteFile(utilsPath, []byte("export function formatDate(d) { return d; }\n"), 0644); err != nil {
+		t.Fatalf("failed to write utils.ts: %v", err)
+	}
+
+	appSrc := "const fs = require('fs');\nformatDate(new Date());\n"
+	appPath := filepath.Join(tempDir, "app.ts")
+	if err := os.WriteFile(appPath, []byte(appSrc), 0644); err != nil {
+		t.Fatalf("failed to write app.ts: %v", err)
+	}
+
+	suggestions := SuggestImportsFor(appPath, "formatDate")
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d: %+v", len(suggestions), suggestions)
+	}
+	if suggestions[0].Edit.Replacement != "const { formatDate } = require('./utils');\n" {
+		t.Errorf("expected a CommonJS-style edit, got %q", suggestions[0].Edit.Replacement)
+	}
+}
+
+func TestSuggestImportsForReturnsNilWhenNothingExports(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-completion-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	appPath := filepath.Join(tempDir, "app.ts")
+	if err := os.WriteFile(appPath, []byte("doSomething();\n"), 0644); err != nil {
+		t.Fatalf("failed to write app.ts: %v", err)
+	}
+
+	if suggestions := SuggestImportsFor(appPath, "doSomething"); suggestions != nil {
+		t.Errorf("expected no suggestions, got %+v", suggestions)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	internalparser "github.com/user/packagefinder/internal/parser"
+	"github.com/user/packagefinder/pkg/finder/noderesolver"
 )
 
 // Regular expressions for detecting different import styles
@@ -19,11 +21,30 @@ var (
 	// CommonJS require patterns
 	requireRegex = regexp.MustCompile(`(?:(?:const|let|var)\s+(?:{([^}]*)}\s*=\s*)?(\w+)\s*=\s*)?require\s*\(\s*['"]([^'"]+)['"]\s*\)`)
 
-	// Dynamic import patterns
-	dynamicImportRegex = regexp.MustCompile(`import\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	// Dynamic import patterns. Beyond a plain string literal, a specifier can
+	// be a template literal (`` import(`${base}/react`) ``) or a bare
+	// identifier (`import(pkg)`) bound by a `const`/`let` earlier in the
+	// file; resolveDynamicSpecifier folds those against fileConstants.
+	dynamicImportRegex = regexp.MustCompile("import\\s*\\(\\s*(?:['\"]([^'\"]+)['\"]|`([^`]*)`|(\\w+))\\s*\\)")
+
+	// SystemJS patterns - same specifier forms as dynamicImportRegex.
+	systemJSRegex = regexp.MustCompile("System\\.import\\s*\\(\\s*(?:['\"]([^'\"]+)['\"]|`([^`]*)`|(\\w+))\\s*\\)")
+
+	// Simple `const`/`let` string assignments and concatenations, used to
+	// seed extractFileConstants: `const base = 'https://cdn.skypack.dev'`,
+	// `const pkg = 'rea' + 'ct'`.
+	constStringAssignRegex = regexp.MustCompile(`(?:const|let)\s+(\w+)\s*=\s*((?:['"][^'"]*['"]\s*\+\s*)*['"][^'"]*['"])\s*;?`)
+	stringLiteralRegex     = regexp.MustCompile(`['"]([^'"]*)['"]`)
 
-	// SystemJS patterns
-	systemJSRegex = regexp.MustCompile(`System\.import\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	// Simple `const`/`let` template-literal assignments, folded against
+	// already-resolved string constants: `` const url = `${base}/react` ``.
+	constTemplateAssignRegex = regexp.MustCompile("(?:const|let)\\s+(\\w+)\\s*=\\s*`([^`]*)`\\s*;?")
+	templateExprRegex        = regexp.MustCompile(`\$\{\s*(\w+)\s*\}`)
+
+	// Cheap presence check for any dynamic-import call form. A file that
+	// has one of these might resolve to packageName only after constant
+	// folding, so quickCheck's literal-substring search can't rule it out.
+	dynamicCallPresentRegex = regexp.MustCompile(`import\s*\(|System\.import\s*\(`)
 
 	// Global variable patterns
 	globalVarRegex = regexp.MustCompile(`(?:window|global)\.(\w+)`)
@@ -34,100 +55,108 @@ var (
 	// UMD factory pattern - new
 	umdFactoryRegex = regexp.MustCompile(`\(\s*function\s*\(\s*(?:root|global|window)(?:\s*,\s*factory)?\s*\)`)
 
-	// ESM import maps - new
-	esmImportMapRegex = regexp.MustCompile(`import\.meta\.resolve\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	// ESM import maps - new. The second, optional argument is the two-arg
+	// form of import.meta.resolve(specifier, parentURL); parentURL may be a
+	// string literal or an expression like `import.meta.url`, so it's
+	// captured as raw text rather than restricted to a quoted literal.
+	esmImportMapRegex = regexp.MustCompile(`import\.meta\.resolve\s*\(\s*['"]([^'"]+)['"]\s*(?:,\s*([^)]+?)\s*)?\)`)
+
+	// Chained .then(...)/.catch(...) right after a dynamic import/System.import call
+	thenChainRegex = regexp.MustCompile(`^(?:\s*\.then\(\s*(?:\(\s*\{([^}]*)\}\s*\)|\(?(\w+)\)?)\s*=>|\s*\.then\(\s*function\s*\(\s*(?:\{([^}]*)\}|(\w+))\s*\))`)
 
 	// Additional patterns can be added as needed
 )
 
-// FindPackageUsage finds all usages of the specified package in the project
-func FindPackageUsage(projectRoot, packageName string) ([]PackageUsage, error) {
-	results := []PackageUsage{}
-
-	// Map to track imported symbols for usage analysis
-	importedSymbolsByFile := make(map[string]map[string]bool)
-
-	// Walk through all files in the project
-	err := filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// extractFileConstants runs a small constant-folding pass over content and
+// returns a map of variable name to resolved string value, for `const`/`let`
+// bindings simple enough to evaluate statically: a plain string literal, a
+// concatenation of string literals, or a template literal whose `${...}`
+// placeholders reference already-resolved constants. It exists so dynamic
+// import forms computed from a file-local variable (`const pkg = 'rea' +
+// 'ct'; import(pkg)`) still resolve to a real specifier instead of being
+// dropped.
+func extractFileConstants(content string) map[string]string {
+	constants := map[string]string{}
+
+	for _, match := range constStringAssignRegex.FindAllStringSubmatch(content, -1) {
+		name, rhs := match[1], match[2]
+		var value strings.Builder
+		for _, lit := range stringLiteralRegex.FindAllStringSubmatch(rhs, -1) {
+			value.WriteString(lit[1])
+		}
+		constants[name] = value.String()
+	}
 
-		// Skip directories, node_modules, and non-JS/TS files
-		if info.IsDir() {
-			// Skip node_modules directory
-			if info.Name() == "node_modules" || info.Name() == ".git" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+	for _, match := range constTemplateAssignRegex.FindAllStringSubmatch(content, -1) {
+		name, template := match[1], match[2]
+		constants[name] = foldTemplateLiteral(template, constants)
+	}
 
-		// Only process JS, JSX, TS, TSX files
-		ext := filepath.Ext(path)
-		if !isJavaScriptFile(ext) {
-			return nil
-		}
+	return constants
+}
 
-		// Read file content
-		content, err := ioutil.ReadFile(path)
-		if err != nil {
-			fmt.Printf("Warning: Could not read file %s: %v\n", path, err)
-			return nil
+// foldTemplateLiteral substitutes `${name}` placeholders in a template
+// literal's body with values from constants, leaving unresolved
+// placeholders untouched since they can't be evaluated statically.
+func foldTemplateLiteral(template string, constants map[string]string) string {
+	return templateExprRegex.ReplaceAllStringFunc(template, func(expr string) string {
+		name := templateExprRegex.FindStringSubmatch(expr)[1]
+		if value, ok := constants[name]; ok {
+			return value
 		}
+		return expr
+	})
+}
 
-		// Search for package usage in the file
-		fileResults, err := findPackageInFile(path, string(content), packageName)
-		if err != nil {
-			fmt.Printf("Warning: Error processing file %s: %v\n", path, err)
-			return nil
-		}
+// resolveDynamicSpecifier extracts the specifier captured by
+// dynamicImportRegex/systemJSRegex at match (a string literal, template
+// literal, or bare identifier, in groups 1-3 respectively) and folds it
+// against constants. ok is false when an identifier specifier isn't a
+// known constant, since there's nothing to match against.
+func resolveDynamicSpecifier(match []int, content string, constants map[string]string) (string, bool) {
+	if match[2] >= 0 && match[3] > match[2] {
+		return content[match[2]:match[3]], true
+	}
+	if match[4] >= 0 {
+		return foldTemplateLiteral(content[match[4]:match[5]], constants), true
+	}
+	if match[6] >= 0 && match[7] > match[6] {
+		value, ok := constants[content[match[6]:match[7]]]
+		return value, ok
+	}
+	return "", false
+}
 
-		// Track imported symbols for later usage analysis
-		if len(fileResults) > 0 {
-			symbolsMap := make(map[string]bool)
-			for _, result := range fileResults {
-				for _, symbol := range result.ImportedSymbols {
-					if symbol != "(side-effect only)" &&
-						symbol != "(dynamic import)" &&
-						symbol != "(require)" &&
-						symbol != "(SystemJS import)" &&
-						symbol != "(UMD factory)" &&
-						symbol != "(AMD require)" &&
-						symbol != "(ImportMaps)" &&
-						symbol != "(SystemJS config)" &&
-						symbol != "(SystemJS register)" {
-						symbolsMap[symbol] = true
-					}
-				}
-			}
-			if len(symbolsMap) > 0 {
-				importedSymbolsByFile[path] = symbolsMap
-			}
+// Option configures a Pipeline built by FindPackageUsage.
+type Option func(*Pipeline)
 
-			results = append(results, fileResults...)
-		}
+// WithImportMap overrides a Pipeline's import map instead of letting each
+// file's scan auto-discover the nearest "import-map.json" by walking up from
+// its own directory. Useful for projects whose import map isn't named or
+// placed conventionally, e.g. one embedded in an HTML
+// `<script type="importmap">` tag and extracted by the caller.
+func WithImportMap(im *ImportMap) Option {
+	return func(p *Pipeline) { p.ImportMap = im }
+}
 
-		return nil
-	})
+// FindPackageUsage finds all usages of the specified package in the project.
+// It runs finder.Pipeline's four stages (Crawl, Parse, Resolve, Aggregate)
+// with their default on-disk cache and worker-pool sizing; callers that want
+// per-phase timings or a custom cache location should drive a Pipeline
+// directly instead.
+func FindPackageUsage(projectRoot, packageName string, opts ...Option) ([]PackageUsage, error) {
+	pipeline := NewPipeline(projectRoot, packageName)
+	for _, opt := range opts {
+		opt(pipeline)
+	}
 
+	results, err := pipeline.Run()
 	if err != nil {
-		return nil, fmt.Errorf("error walking directory: %v", err)
+		return nil, err
 	}
-
-	// Second pass to find symbol usages
-	if len(results) > 0 {
-		for i := range results {
-			filePath := results[i].FileName
-			if symbolsMap, ok := importedSymbolsByFile[filePath]; ok && len(symbolsMap) > 0 {
-				// Find usages of imported symbols
-				symbolUsages, err := findSymbolUsages(filePath, symbolsMap)
-				if err == nil && len(symbolUsages) > 0 {
-					results[i].SymbolUsages = symbolUsages
-				}
-			}
-		}
+	if results == nil {
+		results = []PackageUsage{}
 	}
-
 	return results, nil
 }
 
@@ -136,225 +165,137 @@ func isJavaScriptFile(ext string) bool {
 	return ext == ".js" || ext == ".jsx" || ext == ".ts" || ext == ".tsx"
 }
 
-// findPackageInFile searches for package usage in a single file
-func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, error) {
-	results := []PackageUsage{}
-
-	// Create regex with the package name to search for exact matches
-	packageNameEscaped := regexp.QuoteMeta(packageName)
-
-	// Try direct regex search for quick check
-	quickCheck := regexp.MustCompile(fmt.Sprintf(`['"]%s['"]`, packageNameEscaped))
-	if !quickCheck.MatchString(content) {
-		return results, nil
-	}
-
-	// ES6 imports
-	es6ImportMatches := es6ImportRegex.FindAllStringSubmatchIndex(content, -1)
-	for _, match := range es6ImportMatches {
-		if match == nil || len(match) < 2 {
-			continue
-		}
-
-		// Extract the matched module name - in our updated regex it's in capture group 1
-		moduleStart := match[2]
-		moduleEnd := match[3]
-		if moduleStart < 0 || moduleEnd > len(content) {
-			continue
-		}
-
-		moduleName := content[moduleStart:moduleEnd]
-		if moduleName != packageName {
-			continue
-		}
-
-		// Calculate line number (1-based) and character position (1-based)
-		lineNum, charPos := getLineAndCharacter(content, match[0])
-
-		// Get the full import statement
-		lineStart := strings.LastIndex(content[:match[0]], "\n") + 1
-		if lineStart < 0 {
-			lineStart = 0
-		}
-		lineEnd := match[1]
-		nextNewline := strings.Index(content[lineEnd:], "\n")
-		if nextNewline >= 0 {
-			lineEnd += nextNewline
+// lazyImportMapResolver defers building an ImportMapResolver (which, absent
+// an override, walks filePath's directory up to the filesystem root probing
+// for "import-map.json") until a caller actually needs one, since most files
+// never reach the import-map fallback paths in findPackageInFile.
+func lazyImportMapResolver(filePath string, overrideImportMap ...*ImportMap) func() *ImportMapResolver {
+	var resolver *ImportMapResolver
+	var built bool
+	return func() *ImportMapResolver {
+		if built {
+			return resolver
+		}
+		built = true
+		if len(overrideImportMap) > 0 && overrideImportMap[0] != nil {
+			resolver = NewImportMapResolverFromMap(overrideImportMap[0])
 		} else {
-			lineEnd = len(content)
+			resolver, _ = NewImportMapResolver(filepath.Dir(filePath))
 		}
-		importStatement := strings.TrimSpace(content[lineStart:lineEnd])
-
-		// Extract imported symbols - parse from the importStatement
-		importedSymbols := extractSymbolsFromES6Import(importStatement, packageName)
-
-		results = append(results, PackageUsage{
-			FileName:        filePath,
-			ImportStatement: importStatement,
-			Line:            lineNum,
-			Character:       charPos,
-			ImportedSymbols: importedSymbols,
-			ImportStyle:     ES6Import,
-			IsDynamicImport: false,
-		})
+		return resolver
 	}
+}
 
-	// ES6 import * as NAME from 'package'
-	es6ImportAllMatches := es6ImportAllRegex.FindAllStringSubmatchIndex(content, -1)
-	for _, match := range es6ImportAllMatches {
-		if match == nil || len(match) < 6 {
-			continue
-		}
-
-		// Extract module name
-		moduleName := content[match[4]:match[5]]
-		if moduleName != packageName {
-			continue
-		}
+// findPackageInFile searches for package usage in a single file.
+// overrideImportMap, if given, is used in place of auto-discovering the
+// nearest "import-map.json" by walking up from filePath's directory - see
+// WithImportMap. It's variadic purely so the ~dozen existing call sites
+// (tests included) don't need updating for the common no-override case.
+func findPackageInFile(filePath, content, packageName string, overrideImportMap ...*ImportMap) ([]PackageUsage, error) {
+	results := []PackageUsage{}
 
-		lineNum, charPos := getLineAndCharacter(content, match[0])
+	importMapResolver := lazyImportMapResolver(filePath, overrideImportMap...)
 
-		// Get namespace name
-		namespaceName := content[match[2]:match[3]]
+	// Create regex with the package name to search for exact matches
+	packageNameEscaped := regexp.QuoteMeta(packageName)
 
-		// Get full import statement
-		lineStart := strings.LastIndex(content[:match[0]], "\n") + 1
-		if lineStart < 0 {
-			lineStart = 0
-		}
-		lineEnd := match[1]
-		nextNewline := strings.Index(content[lineEnd:], "\n")
-		if nextNewline >= 0 {
-			lineEnd += nextNewline
-		} else {
-			lineEnd = len(content)
+	// Try direct regex search for quick check. The optional "@types/" prefix
+	// and subpath suffix let this still short-circuit for specifiers like
+	// "@types/react" or "@babel/core/lib/parse" when searching for
+	// "react"/"@babel/core".
+	quickCheck := regexp.MustCompile(fmt.Sprintf(`['"](?:@types/)?%s(?:/[^'"]*)?['"]`, packageNameEscaped))
+	hasDynamicCall := dynamicCallPresentRegex.MatchString(content)
+	if !quickCheck.MatchString(content) && !hasDynamicCall {
+		// The specifier might still reach packageName indirectly through an
+		// import map (e.g. "react-vendor" remapped to a react CDN URL), in
+		// which case the text never literally contains packageName.
+		if len(importMapResolver().specifiersResolvingTo(packageName)) == 0 {
+			return results, nil
 		}
-		importStatement := strings.TrimSpace(content[lineStart:lineEnd])
-
-		results = append(results, PackageUsage{
-			FileName:        filePath,
-			ImportStatement: importStatement,
-			Line:            lineNum,
-			Character:       charPos,
-			ImportedSymbols: []string{"* as " + namespaceName},
-			ImportStyle:     ES6Import,
-			IsDynamicImport: false,
-		})
 	}
 
-	// CommonJS require
-	requireMatches := requireRegex.FindAllStringSubmatchIndex(content, -1)
-	for _, match := range requireMatches {
-		if match == nil || match[6] < 0 || match[7] <= match[6] {
-			continue
-		}
-
-		// Extract module name
-		moduleName := content[match[6]:match[7]]
-		if moduleName != packageName {
-			continue
-		}
-
-		lineNum, charPos := getLineAndCharacter(content, match[0])
-
-		// Get full require statement
-		lineStart := strings.LastIndex(content[:match[0]], "\n") + 1
-		if lineStart < 0 {
-			lineStart = 0
-		}
-		lineEnd := match[1]
-		nextNewline := strings.Index(content[lineEnd:], "\n")
-		if nextNewline >= 0 {
-			lineEnd += nextNewline
-		} else {
-			lineEnd = len(content)
-		}
-		importStatement := strings.TrimSpace(content[lineStart:lineEnd])
-
-		// Extract imported symbols
-		importedSymbols := []string{}
-
-		// Add the variable name (module reference)
-		if match[4] > 0 && match[5] > match[4] {
-			varName := content[match[4]:match[5]]
-			importedSymbols = append(importedSymbols, strings.TrimSpace(varName))
-		}
+	// Resolve file-local const/let string bindings, needed only when a
+	// dynamic import call is actually present, so a folded specifier
+	// (`const pkg = 'rea' + 'ct'; import(pkg)`) isn't missed just because
+	// it never appears as a literal quoted string above.
+	var fileConstants map[string]string
+	if hasDynamicCall {
+		fileConstants = extractFileConstants(content)
+	}
 
-		// Check for destructuring
-		if match[2] > 0 && match[3] > match[2] {
-			destructuring := content[match[2]:match[3]]
-			for _, symbol := range strings.Split(destructuring, ",") {
-				symbol = strings.TrimSpace(symbol)
-
-				// Handle aliased requires like { originalName: aliasName }
-				if strings.Contains(symbol, ":") {
-					parts := strings.Split(symbol, ":")
-					if len(parts) == 2 {
-						symbol = strings.TrimSpace(parts[1])
-					}
-				}
+	// ES6 imports, `import * as NAME`, and CommonJS require sites are
+	// detected by walking Parser's node stream (ASTParser, backed by a real
+	// ECMAScript parser, with RegexParser as its documented fallback)
+	// instead of matching regexes directly against content here, so a
+	// multi-line named-import list or the word "require" sitting inside a
+	// comment/string is handled the way the grammar says to rather than by
+	// how well a regex approximates it.
+	nodes, err := defaultParser().Parse(filePath, content)
+	if err != nil {
+		return nil, err
+	}
 
-				if symbol != "" {
-					importedSymbols = append(importedSymbols, symbol)
-				}
+	for _, node := range nodes {
+		switch node.Kind {
+		case NodeES6Import:
+			usage, ok := resolveES6ImportNode(filePath, content, packageName, node, importMapResolver())
+			if ok {
+				results = append(results, usage)
 			}
-		}
-
-		// If no symbols were extracted, use module name as a fallback
-		if len(importedSymbols) == 0 {
-			// Try to extract variable name from context
-			varNamePattern := regexp.MustCompile(`(?:const|let|var)\s+(\w+)\s*=\s*require\s*\(`)
-			varNameMatch := varNamePattern.FindStringSubmatch(importStatement)
-			if varNameMatch != nil && len(varNameMatch) > 1 {
-				importedSymbols = append(importedSymbols, varNameMatch[1])
-			} else {
-				importedSymbols = append(importedSymbols, packageName)
+		case NodeES6ImportAll:
+			if !matchesPackageName(node.ModuleName, packageName, DefaultMatchOptions()) {
+				continue
 			}
-		}
-
-		// Check if this is a require for a React or React-like package and also add PascalCase version if needed
-		if strings.ToLower(packageName) == "react" || strings.HasPrefix(strings.ToLower(packageName), "react-") {
-			// Add both lowercase and uppercase versions for React packages
-			hasLowerCase := false
-			hasUpperCase := false
-
-			for _, symbol := range importedSymbols {
-				if symbol == "react" || symbol == packageName {
-					hasLowerCase = true
-				}
-				if symbol == "React" {
-					hasUpperCase = true
+			lineNum, charPos := getLineAndCharacter(content, node.Start)
+			results = append(results, PackageUsage{
+				FileName:        filePath,
+				ImportStatement: node.Statement,
+				Line:            lineNum,
+				Character:       charPos,
+				ImportedSymbols: node.Symbols,
+				ImportStyle:     ES6Import,
+				IsDynamicImport: false,
+			})
+		case NodeCommonJSRequire:
+			if !matchesPackageName(node.ModuleName, packageName, DefaultMatchOptions()) {
+				continue
+			}
+			lineNum, charPos := getLineAndCharacter(content, node.Start)
+
+			importedSymbols := append([]string{}, node.Symbols...)
+			if len(importedSymbols) == 0 {
+				// Bare `require('pkg')` with no assignment - try to recover
+				// a binding name from the statement, else fall back to the
+				// package name itself.
+				varNamePattern := regexp.MustCompile(`(?:const|let|var)\s+(\w+)\s*=\s*require\s*\(`)
+				if varNameMatch := varNamePattern.FindStringSubmatch(node.Statement); varNameMatch != nil {
+					importedSymbols = append(importedSymbols, varNameMatch[1])
+				} else {
+					importedSymbols = append(importedSymbols, packageName)
 				}
 			}
 
-			// Add both casing variations if they don't already exist
-			if hasLowerCase && !hasUpperCase {
-				importedSymbols = append(importedSymbols, "React")
-			} else if hasUpperCase && !hasLowerCase && packageName == "react" {
-				importedSymbols = append(importedSymbols, "react")
-			}
+			results = append(results, PackageUsage{
+				FileName:        filePath,
+				ImportStatement: node.Statement,
+				Line:            lineNum,
+				Character:       charPos,
+				ImportedSymbols: importedSymbols,
+				ImportStyle:     CommonJS,
+				IsDynamicImport: false,
+			})
 		}
-
-		results = append(results, PackageUsage{
-			FileName:        filePath,
-			ImportStatement: importStatement,
-			Line:            lineNum,
-			Character:       charPos,
-			ImportedSymbols: importedSymbols,
-			ImportStyle:     CommonJS,
-			IsDynamicImport: false,
-		})
 	}
 
 	// Dynamic imports: import('package-name')
 	dynamicImportMatches := dynamicImportRegex.FindAllStringSubmatchIndex(content, -1)
 	for _, match := range dynamicImportMatches {
-		if match == nil || match[2] < 0 || match[3] <= match[2] {
+		if match == nil {
 			continue
 		}
 
-		moduleName := content[match[2]:match[3]]
-		if moduleName != packageName {
+		moduleName, ok := resolveDynamicSpecifier(match, content, fileConstants)
+		if !ok || !matchesPackageName(moduleName, packageName, DefaultMatchOptions()) {
 			continue
 		}
 
@@ -374,13 +315,20 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 		}
 		importStatement := strings.TrimSpace(content[lineStart:lineEnd])
 
-		// Try to find variable name from surrounding context
-		varName := extractImportedNameFromContext(importStatement, content, lineStart)
-		importedSymbols := []string{}
-		if varName != "" {
-			importedSymbols = append(importedSymbols, varName)
-		} else {
-			importedSymbols = append(importedSymbols, packageName)
+		// A chained `.then(f)`/`.catch(g)` (or both) right after the call
+		// destructures the resolved module, e.g.
+		// import('react-router').then(({ useHistory }) => ...); prefer those
+		// symbols over a guessed variable name.
+		importedSymbols := extractThenChainSymbols(content, match[1])
+		if len(importedSymbols) == 0 {
+			// Try to find variable name from surrounding context
+			varName := extractImportedNameFromContext(importStatement, content, lineStart)
+			if varName != "" {
+				importedSymbols = append(importedSymbols, varName)
+				importedSymbols = append(importedSymbols, extractAwaitedMemberAccesses(content, varName, match[1])...)
+			} else {
+				importedSymbols = append(importedSymbols, packageName)
+			}
 		}
 
 		results = append(results, PackageUsage{
@@ -397,12 +345,12 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 	// System.js imports
 	systemMatches := systemJSRegex.FindAllStringSubmatchIndex(content, -1)
 	for _, match := range systemMatches {
-		if match == nil || match[2] < 0 || match[3] <= match[2] {
+		if match == nil {
 			continue
 		}
 
-		moduleName := content[match[2]:match[3]]
-		if moduleName != packageName {
+		moduleName, ok := resolveDynamicSpecifier(match, content, fileConstants)
+		if !ok || !matchesPackageName(moduleName, packageName, DefaultMatchOptions()) {
 			continue
 		}
 
@@ -423,12 +371,14 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 		importStatement := strings.TrimSpace(content[lineStart:lineEnd])
 
 		// Try to extract variable name from context
-		varName := extractImportedNameFromContext(importStatement, content, lineStart)
-		importedSymbols := []string{}
-		if varName != "" {
-			importedSymbols = append(importedSymbols, varName)
-		} else {
-			importedSymbols = append(importedSymbols, packageName)
+		importedSymbols := extractThenChainSymbols(content, match[1])
+		if len(importedSymbols) == 0 {
+			varName := extractImportedNameFromContext(importStatement, content, lineStart)
+			if varName != "" {
+				importedSymbols = append(importedSymbols, varName)
+			} else {
+				importedSymbols = append(importedSymbols, packageName)
+			}
 		}
 
 		results = append(results, PackageUsage{
@@ -489,171 +439,32 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 		})
 	}
 
-	// AMD define - new
-	amdMatches := amdDefineRegex.FindAllStringSubmatchIndex(content, -1)
-	for _, match := range amdMatches {
-		if match == nil || match[2] < 0 || match[3] <= match[2] {
+	// AMD define(). One ImportNode per dependency is already in nodes
+	// (RegexParser's visitAMDDefines, merged in by ASTParser since AMD isn't
+	// part of the ES grammar); extractAMDParameterName recovers the factory
+	// parameter bound to our dependency's position. Parsed once up front so a
+	// file with several define() calls doesn't re-walk the AST per dependency.
+	var amdFile *internalparser.File
+	for _, node := range nodes {
+		if node.Kind != NodeAMDDefine || node.ModuleName != packageName {
 			continue
 		}
-
-		// Get the dependencies array as string
-		dependenciesStr := content[match[2]:match[3]]
-
-		// Check if our package is in the dependencies
-		packageMatch := regexp.MustCompile(fmt.Sprintf(`['"]%s['"]`, packageNameEscaped)).FindStringIndex(dependenciesStr)
-		if packageMatch == nil {
-			continue
+		if amdFile == nil {
+			amdFile, _ = internalparser.ParseSource(filePath, content)
 		}
 
-		lineNum, charPos := getLineAndCharacter(content, match[0])
-
-		// Get full import statement - get the whole line from the beginning
-		lineStart := strings.LastIndex(content[:match[0]], "\n") + 1
-		if lineStart < 0 {
-			lineStart = 0
-		}
-
-		// Find the end of the define call
-		startOfDefine := match[0]
-		endOfDefine := len(content) // default to end of file
-		openParens := 1
-		closingBrace := -1
-
-		// Find closing parenthesis of define call
-		for i := startOfDefine + 6; i < len(content) && i < startOfDefine+1000; i++ {
-			if content[i] == '(' {
-				openParens++
-			} else if content[i] == ')' {
-				openParens--
-				if openParens == 0 {
-					closingBrace = i
-					break
-				}
-			}
-		}
-
-		if closingBrace > startOfDefine {
-			endOfDefine = closingBrace + 1
-
-			// Check if there's a semicolon to include
-			if endOfDefine < len(content) && content[endOfDefine] == ';' {
-				endOfDefine++
-			}
-		}
-
-		// Get the complete define statement
-		importStatement := strings.TrimSpace(content[lineStart:endOfDefine])
-
-		// Truncate lengthy statements for readability
-		if len(importStatement) > 100 {
-			// Prioritize showing the function parameters
-			funcParamIdx := strings.Index(importStatement, "function(")
-			if funcParamIdx > 0 && funcParamIdx < 80 {
-				// Find the closing parenthesis
-				closeParen := strings.Index(importStatement[funcParamIdx:], ")")
-				if closeParen > 0 {
-					closeParenIdx := funcParamIdx + closeParen + 1
-					if closeParenIdx < len(importStatement) {
-						importStatement = importStatement[:closeParenIdx] + " {...}"
-					}
-				}
-			} else {
-				// Show the dependencies part
-				depsStart := strings.Index(importStatement, "[")
-				depsEnd := strings.Index(importStatement, "]")
-				if depsStart > 0 && depsEnd > depsStart && depsEnd < 100 {
-					importStatement = importStatement[:depsEnd+1] + ", function(...) {...}"
-				} else {
-					// Simple truncation
-					importStatement = importStatement[:97] + "..."
-				}
-			}
-		}
+		lineNum, charPos := getLineAndCharacter(content, node.Start)
 
-		// Find the parameter name corresponding to our package
-		// Let's extract the dependencies and parameters directly from the import statement first
 		importedSymbols := []string{}
-
-		// Parse the AMD define arguments more precisely
-		depsRegex := regexp.MustCompile(`\[\s*([^\]]*)\s*\]`)
-		depsMatch := depsRegex.FindStringSubmatch(importStatement)
-
-		if depsMatch != nil && len(depsMatch) > 1 {
-			// Extract dependencies
-			deps := []string{}
-			depNamesRegex := regexp.MustCompile(`['"]([^'"]+)['"]`)
-			depMatches := depNamesRegex.FindAllStringSubmatch(depsMatch[1], -1)
-
-			for _, depMatch := range depMatches {
-				if len(depMatch) > 1 {
-					deps = append(deps, depMatch[1])
-				}
-			}
-
-			// Find our package's index
-			packageIndex := -1
-			for i, dep := range deps {
-				if dep == packageName {
-					packageIndex = i
-					break
-				}
-			}
-
-			// Extract parameters if we found our package
-			if packageIndex >= 0 {
-				// Look for function parameters
-				paramsRegex := regexp.MustCompile(`function\s*\(\s*([^)]*)\s*\)`)
-				paramsMatch := paramsRegex.FindStringSubmatch(importStatement)
-
-				if paramsMatch != nil && len(paramsMatch) > 1 {
-					// Split parameters
-					params := []string{}
-					for _, param := range strings.Split(paramsMatch[1], ",") {
-						trimmedParam := strings.TrimSpace(param)
-						if trimmedParam != "" {
-							params = append(params, trimmedParam)
-						}
-					}
-
-					// Find the parameter corresponding to our package index
-					if packageIndex < len(params) {
-						// We found the parameter name!
-						importedSymbols = append(importedSymbols, params[packageIndex])
-					}
-				}
-			}
-		}
-
-		// Fallback if we couldn't extract the parameter
-		if len(importedSymbols) == 0 {
+		if param := extractAMDParameterName(amdFile, node.Start, packageName); param != "" {
+			importedSymbols = append(importedSymbols, param)
+		} else {
 			importedSymbols = append(importedSymbols, packageName)
 		}
 
-		// Check if this is React or React-like package, add both casing variants
-		if strings.ToLower(packageName) == "react" || strings.HasPrefix(strings.ToLower(packageName), "react-") {
-			hasLowerCase := false
-			hasUpperCase := false
-
-			for _, symbol := range importedSymbols {
-				if symbol == "react" || symbol == packageName {
-					hasLowerCase = true
-				}
-				if symbol == "React" {
-					hasUpperCase = true
-				}
-			}
-
-			// Add both casing variations if they don't already exist
-			if hasLowerCase && !hasUpperCase {
-				importedSymbols = append(importedSymbols, "React")
-			} else if hasUpperCase && !hasLowerCase && packageName == "react" {
-				importedSymbols = append(importedSymbols, "react")
-			}
-		}
-
 		results = append(results, PackageUsage{
 			FileName:        filePath,
-			ImportStatement: importStatement,
+			ImportStatement: node.Statement,
 			Line:            lineNum,
 			Character:       charPos,
 			ImportedSymbols: importedSymbols,
@@ -662,16 +473,16 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 		})
 	}
 
-	// UMD Factory Pattern - new
-	umdMatches := umdFactoryRegex.FindAllStringIndex(content, -1)
-	for _, match := range umdMatches {
-		if match == nil {
+	// UMD factory pattern. NodeUMDFactory only marks where the wrapper
+	// starts (the grammar has nothing to say about its body), so the
+	// factory's own content window and parameter list are still recovered
+	// directly from content.
+	for _, node := range nodes {
+		if node.Kind != NodeUMDFactory {
 			continue
 		}
 
-		// The UMD pattern detection is more complex - we need to check the entire factory wrapper
-		// For now, do a simple check: look for the package name inside the UMD factory
-		factoryStart := match[0]
+		factoryStart := node.Start
 		factoryEnd := len(content)
 
 		// Try to find the end of the UMD pattern (simplified)
@@ -688,7 +499,7 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 			continue
 		}
 
-		lineNum, charPos := getLineAndCharacter(content, match[0])
+		lineNum, charPos := getLineAndCharacter(content, node.Start)
 
 		// Get a concise representation of the UMD pattern
 		importStatement := "UMD factory pattern with reference to " + packageName
@@ -712,35 +523,6 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 			importedSymbols = append(importedSymbols, packageName)
 		}
 
-		// Check if React or React-like package is being used in UMD
-		if strings.ToLower(packageName) == "react" || strings.HasPrefix(strings.ToLower(packageName), "react-") {
-			// Check for explicit React usage in the factory content
-			reactUsage := regexp.MustCompile(`(?:root\.React|React\.|React\s*,|,\s*React)`).FindString(factoryContent)
-			if reactUsage != "" && !contains(importedSymbols, "React") {
-				importedSymbols = append(importedSymbols, "React")
-			}
-
-			// Make sure we have both casing variants if appropriate
-			hasLowerCase := false
-			hasUpperCase := false
-
-			for _, symbol := range importedSymbols {
-				if symbol == "react" || symbol == packageName {
-					hasLowerCase = true
-				}
-				if symbol == "React" {
-					hasUpperCase = true
-				}
-			}
-
-			// Add both casing variations if appropriate
-			if hasLowerCase && !hasUpperCase {
-				importedSymbols = append(importedSymbols, "React")
-			} else if hasUpperCase && !hasLowerCase && packageName == "react" {
-				importedSymbols = append(importedSymbols, "react")
-			}
-		}
-
 		results = append(results, PackageUsage{
 			FileName:        filePath,
 			ImportStatement: importStatement,
@@ -760,10 +542,16 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 		}
 
 		moduleName := content[match[2]:match[3]]
-		if moduleName != packageName {
+		if !matchesPackageName(moduleName, packageName, DefaultMatchOptions()) {
 			continue
 		}
 
+		// Two-argument form: import.meta.resolve(specifier, parentURL).
+		parentURL := ""
+		if match[4] >= 0 && match[5] > match[4] {
+			parentURL = content[match[4]:match[5]]
+		}
+
 		lineNum, charPos := getLineAndCharacter(content, match[0])
 
 		// Get full import statement
@@ -796,47 +584,6 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 			}
 		}
 
-		// Special handling for React and React-related packages
-		if strings.ToLower(packageName) == "react" || strings.HasPrefix(strings.ToLower(packageName), "react-") {
-			// Check for React usage in surrounding context
-			contextStart := lineStart - 200
-			if contextStart < 0 {
-				contextStart = 0
-			}
-			contextEnd := lineEnd + 200
-			if contextEnd > len(content) {
-				contextEnd = len(content)
-			}
-
-			surroundingContext := content[contextStart:contextEnd]
-			reactUsage := regexp.MustCompile(`React\.`).FindString(surroundingContext)
-
-			// Add React symbol if used in context but not already in symbols
-			if reactUsage != "" && !contains(importedSymbols, "React") {
-				importedSymbols = append(importedSymbols, "React")
-			}
-
-			// Make sure we have both casing variants if appropriate
-			hasLowerCase := false
-			hasUpperCase := false
-
-			for _, symbol := range importedSymbols {
-				if symbol == "react" || symbol == packageName {
-					hasLowerCase = true
-				}
-				if symbol == "React" {
-					hasUpperCase = true
-				}
-			}
-
-			// Add both casing variations
-			if hasLowerCase && !hasUpperCase {
-				importedSymbols = append(importedSymbols, "React")
-			} else if hasUpperCase && !hasLowerCase && packageName == "react" {
-				importedSymbols = append(importedSymbols, "react")
-			}
-		}
-
 		results = append(results, PackageUsage{
 			FileName:        filePath,
 			ImportStatement: importStatement,
@@ -845,46 +592,192 @@ func findPackageInFile(filePath, content, packageName string) ([]PackageUsage, e
 			ImportedSymbols: importedSymbols,
 			ImportStyle:     ImportMaps,
 			IsDynamicImport: true,
+			ParentURL:       parentURL,
 		})
 	}
 
 	return results, nil
 }
 
-// extractSymbolsFromES6Import extracts imported symbols from an ES6 import statement
-func extractSymbolsFromES6Import(importStatement, packageName string) []string {
-	importedSymbols := []string{}
+// defaultParser is the Parser findPackageInFile walks. It's a var, not a
+// bare NewASTParser() call at the use site, so tests can swap in a
+// RegexParser-only stub without findPackageInFile itself knowing.
+var defaultParser = func() Parser { return NewASTParser() }
+
+// resolveES6ImportNode turns a NodeES6Import node into the PackageUsage it
+// represents, honoring the same precedence the inline regex scan used to:
+// an import-map remap, then a tsconfig path alias, then a `#`-prefixed
+// subpath import, and finally a plain node_modules resolution. ok is false
+// when node's module doesn't resolve to packageName by any of those routes.
+func resolveES6ImportNode(filePath, content, packageName string, node ImportNode, importMapResolver *ImportMapResolver) (PackageUsage, bool) {
+	moduleName := node.ModuleName
+
+	// A specifier that doesn't name packageName directly may still refer to
+	// it through an import map (e.g. "react" remapped to a CDN URL, or an
+	// alias like "~/react-alias" remapped to "react").
+	importMapField, importMapConditions := "", []string(nil)
+	directMatch := matchesPackageName(moduleName, packageName, DefaultMatchOptions())
+	if !directMatch {
+		if target, scopeKey, ok := importMapResolver.Resolve(moduleName, filePath); ok &&
+			matchesPackageName(canonicalPackageIdentity(target), packageName, DefaultMatchOptions()) {
+			directMatch = true
+			importMapField = "importmap"
+			if scopeKey != "" {
+				importMapConditions = []string{"scopes:" + scopeKey}
+			} else {
+				importMapConditions = []string{"imports"}
+			}
+		}
+	}
+	if !directMatch {
+		return PackageUsage{}, false
+	}
 
-	// Match default import: import React from 'react'
-	defaultImportRegex := regexp.MustCompile(`import\s+(\w+)(?:\s*,\s*|\s+from\s+)`)
-	defaultMatch := defaultImportRegex.FindStringSubmatch(importStatement)
-	if defaultMatch != nil && len(defaultMatch) > 1 {
-		importedSymbols = append(importedSymbols, defaultMatch[1])
+	lineNum, charPos := getLineAndCharacter(content, node.Start)
+	importedSymbols := node.Symbols
+
+	// If the specifier resolves to a local file via a tsconfig path alias,
+	// it isn't really a usage of a node package with the same name
+	// (esbuild's --packages=external treats aliases the same way), so
+	// report it as a PathAlias instead of an ES6Import match.
+	if resolver, err := NewTSConfigResolver(filepath.Dir(filePath)); err == nil {
+		if aliasTarget, ok := resolver.ResolveAlias(moduleName); ok {
+			return PackageUsage{
+				FileName:        filePath,
+				ImportStatement: node.Statement,
+				Line:            lineNum,
+				Character:       charPos,
+				ImportedSymbols: importedSymbols,
+				ImportStyle:     PathAlias,
+				IsDynamicImport: false,
+				SymbolResolutions: []SymbolResolution{{
+					SymbolName:           packageName,
+					ResolvedFrom:         moduleName,
+					ActualDefinitionPath: aliasTarget,
+				}},
+				MatchedField: "tsconfig-path",
+			}, true
+		}
 	}
 
-	// Match named imports: import { useState, useEffect } from 'react'
-	namedImportRegex := regexp.MustCompile(`import\s+{([^}]*)}`)
-	namedMatch := namedImportRegex.FindStringSubmatch(importStatement)
-	if namedMatch != nil && len(namedMatch) > 1 {
-		namedImports := namedMatch[1]
-		for _, symbol := range strings.Split(namedImports, ",") {
-			symbol = strings.TrimSpace(symbol)
-
-			// Handle aliased imports like { originalName as aliasName }
-			if strings.Contains(symbol, " as ") {
-				parts := strings.Split(symbol, " as ")
-				if len(parts) == 2 {
-					symbol = strings.TrimSpace(parts[1])
-				}
-			}
+	// `#foo` specifiers are package-scoped subpath imports, resolved
+	// against the importing package's own package.json `imports` field
+	// rather than treated as a regular ES6 package import.
+	if strings.HasPrefix(moduleName, "#") {
+		subpathResolutions, subpathConditions := resolveSubpathSymbols(filePath, moduleName, importedSymbols)
+		return PackageUsage{
+			FileName:           filePath,
+			ImportStatement:    node.Statement,
+			Line:               lineNum,
+			Character:          charPos,
+			ImportedSymbols:    importedSymbols,
+			ImportStyle:        SubpathImport,
+			IsDynamicImport:    false,
+			SymbolResolutions:  subpathResolutions,
+			MatchedField:       "imports",
+			ResolvedConditions: subpathConditions,
+		}, true
+	}
 
-			if symbol != "" {
-				importedSymbols = append(importedSymbols, symbol)
-			}
+	symbolResolutions, matchedField, conditionPath := resolveImportedSymbols(filePath, packageName, moduleName, importedSymbols)
+	resolvedConditions := importMapConditions
+	if importMapField != "" {
+		matchedField = importMapField
+	} else {
+		resolvedConditions = conditionPath
+	}
+
+	return PackageUsage{
+		FileName:           filePath,
+		ImportStatement:    node.Statement,
+		Line:               lineNum,
+		Character:          charPos,
+		ImportedSymbols:    importedSymbols,
+		ImportStyle:        ES6Import,
+		IsDynamicImport:    false,
+		SymbolResolutions:  symbolResolutions,
+		MatchedField:       matchedField,
+		ResolvedConditions: resolvedConditions,
+	}, true
+}
+
+// resolveImportedSymbols resolves moduleName's real entry point (honoring
+// exports/module/main/browser field ordering, a subpath like
+// "react/jsx-runtime" against the package's `exports` map, and falling
+// back to @types/*), attaches it to every symbol imported from it in this
+// statement, and returns the package.json field the entry point came from
+// plus any `exports`/`imports` condition keys selected along the way (e.g.
+// ["node", "import"]), so a caller can see why a usage was attributed.
+// packageName (the bare name being matched against, as opposed to
+// moduleName's possible subpath) is what gets reported as ResolvedFrom,
+// matching how a non-deep import is already reported.
+func resolveImportedSymbols(filePath, packageName, moduleName string, importedSymbols []string) ([]SymbolResolution, string, []string) {
+	resolver := noderesolver.NewResolver(filepath.Dir(filePath))
+	resolution, err := resolver.Resolve(moduleName, noderesolver.PlatformNode)
+	if err != nil {
+		return nil, "", nil
+	}
+
+	resolutions := make([]SymbolResolution, 0, len(importedSymbols))
+	for _, symbol := range importedSymbols {
+		resolutions = append(resolutions, SymbolResolution{
+			SymbolName:           symbol,
+			ResolvedFrom:         packageName,
+			ActualDefinitionPath: resolution.EntryPath,
+			IsFromTypeDefinition: resolution.IsFromTypeDefinition,
+		})
+	}
+	return resolutions, resolution.MatchedField, resolution.ConditionPath
+}
+
+// resolveSubpathSymbols resolves a `#`-prefixed subpath specifier against the
+// importing package's own `imports` field, including the "node" condition
+// mapping to a built-in module name, and returns the condition keys
+// selected while resolving it alongside the symbol resolutions.
+func resolveSubpathSymbols(filePath, specifier string, importedSymbols []string) ([]SymbolResolution, []string) {
+	resolution, err := noderesolver.ResolveSubpathImport(filepath.Dir(filePath), specifier)
+	if err != nil {
+		return nil, nil
+	}
+
+	resolutions := make([]SymbolResolution, 0, len(importedSymbols))
+	for _, symbol := range importedSymbols {
+		resolutions = append(resolutions, SymbolResolution{
+			SymbolName:           symbol,
+			ResolvedFrom:         specifier,
+			ActualDefinitionPath: resolution.EntryPath,
+		})
+	}
+	return resolutions, resolution.ConditionPath
+}
+
+// extractSymbolsFromES6Import extracts imported symbols from a single ES6
+// import statement by parsing it with internal/parser rather than matching
+// the default/named-import clauses with their own regexes, so an aliased
+// named import, a trailing comment inside the braces, or an unusual amount
+// of whitespace is read the way the grammar says to.
+func extractSymbolsFromES6Import(importStatement, packageName string) []string {
+	file, err := internalparser.ParseSource("", importStatement)
+	if err != nil || len(file.Imports) == 0 {
+		return []string{"(side-effect only)"}
+	}
+	decl := file.Imports[0]
+
+	importedSymbols := []string{}
+	if decl.Default != "" {
+		importedSymbols = append(importedSymbols, decl.Default)
+	}
+	if decl.Namespace != "" {
+		importedSymbols = append(importedSymbols, "* as "+decl.Namespace)
+	}
+	for _, named := range decl.Named {
+		if named.Alias != "" {
+			importedSymbols = append(importedSymbols, named.Alias)
+		} else {
+			importedSymbols = append(importedSymbols, named.Name)
 		}
 	}
 
-	// If no named or default imports were found, this is a side-effect-only import
 	if len(importedSymbols) == 0 {
 		importedSymbols = append(importedSymbols, "(side-effect only)")
 	}
@@ -924,6 +817,13 @@ func findSymbolUsages(filePath string, symbolsMap map[string]bool) ([]SymbolUsag
 	symbolPattern := fmt.Sprintf(`\b(%s)(?:\b|\.)`, strings.Join(symbols, "|"))
 	symbolRegex := regexp.MustCompile(symbolPattern)
 
+	// Declaration sites (import/require/dynamic-import/AMD-define) are
+	// excluded below by the byte ranges internal/parser reports for them,
+	// rather than by guessing from line text whether it "looks like" one -
+	// a guess that a destructuring default value on the same line as a
+	// require() could easily defeat.
+	declarationRanges := declarationRangesOf(string(content))
+
 	// Scan file for symbol usages
 	matches := symbolRegex.FindAllStringSubmatchIndex(string(content), -1)
 	for _, match := range matches {
@@ -933,26 +833,7 @@ func findSymbolUsages(filePath string, symbolsMap map[string]bool) ([]SymbolUsag
 
 		symbol := string(content[match[2]:match[3]])
 
-		// Skip if this is inside an import statement or require call
-		lineStart := strings.LastIndex(string(content[:match[0]]), "\n") + 1
-		if lineStart < 0 {
-			lineStart = 0
-		}
-		lineEnd := match[1]
-		nextNewline := strings.Index(string(content[lineEnd:]), "\n")
-		if nextNewline >= 0 {
-			lineEnd += nextNewline
-		} else {
-			lineEnd = len(content)
-		}
-
-		line := string(content[lineStart:lineEnd])
-
-		// Skip if this is part of an import statement or require call
-		if strings.Contains(line, "import") && strings.Contains(line, "from") {
-			continue
-		}
-		if strings.Contains(line, "require(") {
+		if withinAny(declarationRanges, match[0]) {
 			continue
 		}
 
@@ -991,6 +872,43 @@ func findSymbolUsages(filePath string, symbolsMap map[string]bool) ([]SymbolUsag
 	return result, nil
 }
 
+// declarationRangesOf returns the [Start,End) byte span of every import,
+// require, dynamic-import, and AMD-define site internal/parser finds in
+// content, so findSymbolUsages can exclude a symbol match that's part of
+// the declaration itself (e.g. the "react" in `require('react')`) rather
+// than a real usage.
+func declarationRangesOf(content string) [][2]int {
+	file, err := internalparser.ParseSource("", content)
+	if err != nil {
+		return nil
+	}
+
+	ranges := make([][2]int, 0, len(file.Imports)+len(file.Requires)+len(file.DynamicImports)+len(file.AMDDefines))
+	for _, decl := range file.Imports {
+		ranges = append(ranges, [2]int{decl.Start, decl.End})
+	}
+	for _, req := range file.Requires {
+		ranges = append(ranges, [2]int{req.Start, req.End})
+	}
+	for _, di := range file.DynamicImports {
+		ranges = append(ranges, [2]int{di.Start, di.End})
+	}
+	for _, def := range file.AMDDefines {
+		ranges = append(ranges, [2]int{def.Start, def.End})
+	}
+	return ranges
+}
+
+// withinAny reports whether offset falls inside any of ranges.
+func withinAny(ranges [][2]int, offset int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
 // getLineAndCharacter calculates the line number and character position for an offset
 func getLineAndCharacter(content string, offset int) (int, int) {
 	lines := strings.Split(content[:offset], "\n")
@@ -1048,84 +966,91 @@ func PrintResults(results []PackageUsage) {
 	}
 }
 
-// extractAMDParameterName tries to extract the parameter name for an AMD module
-func extractAMDParameterName(content string, defineStart int, packageName string) string {
-	// Get the full define(...) call with its callback function
-	openParens := 1
-	closeDefinePos := defineStart
-
-	for i := defineStart + 1; i < len(content); i++ {
-		if content[i] == '(' {
-			openParens++
-		} else if content[i] == ')' {
-			openParens--
-			if openParens == 0 {
-				closeDefinePos = i + 1
-				break
-			}
-		}
+// extractThenChainSymbols looks for a `.then(...)` (arrow or `function`)
+// immediately following a dynamic import/System.import call at callEnd and,
+// if the callback destructures its parameter (e.g. `({ useHistory }) => `),
+// returns the destructured names. A plain (non-destructured) parameter name
+// is not returned here since it's a module reference, not an imported symbol.
+func extractThenChainSymbols(content string, callEnd int) []string {
+	if callEnd < 0 || callEnd > len(content) {
+		return nil
 	}
 
-	// Get the full define call
-	defineCall := content[defineStart:closeDefinePos]
-
-	// Find the module name in the dependencies array
-	packageNameEscaped := regexp.QuoteMeta(packageName)
-	// Updated regex to match more precisely
-	moduleRegex := regexp.MustCompile(fmt.Sprintf(`\[([^\]]*?)['"]%s['"]([^\]]*?)\]`, packageNameEscaped))
-	moduleMatch := moduleRegex.FindStringSubmatch(defineCall)
-	if moduleMatch == nil || len(moduleMatch) < 3 {
-		return ""
+	match := thenChainRegex.FindStringSubmatch(content[callEnd:])
+	if match == nil {
+		return nil
 	}
 
-	// Count the position of our module in the dependencies array
-	beforeModule := moduleMatch[1]
-	afterModule := moduleMatch[2]
-
-	// Improved position calculation
-	deps := []string{}
-
-	// Extract all dependencies
-	depsRegex := regexp.MustCompile(`['"]([^'"]+)['"]`)
-	depsMatches := depsRegex.FindAllStringSubmatch(fmt.Sprintf("%s'%s'%s", beforeModule, packageName, afterModule), -1)
-
-	for _, match := range depsMatches {
-		if len(match) > 1 {
-			deps = append(deps, match[1])
-		}
+	destructured := match[1]
+	if destructured == "" {
+		destructured = match[3]
+	}
+	if destructured == "" {
+		return nil
 	}
 
-	// Find the index of our package
-	moduleIndex := -1
-	for i, dep := range deps {
-		if dep == packageName {
-			moduleIndex = i
-			break
+	symbols := []string{}
+	for _, symbol := range strings.Split(destructured, ",") {
+		symbol = strings.TrimSpace(symbol)
+		if strings.Contains(symbol, ":") {
+			parts := strings.Split(symbol, ":")
+			if len(parts) == 2 {
+				symbol = strings.TrimSpace(parts[1])
+			}
+		}
+		if symbol != "" {
+			symbols = append(symbols, symbol)
 		}
 	}
+	return symbols
+}
 
-	if moduleIndex == -1 {
-		return ""
+// extractAwaitedMemberAccesses flow-tracks a binding created by `const
+// varName = await import(...)` and returns the member names accessed off it
+// shortly after (e.g. `r.useHistory()` -> "useHistory").
+func extractAwaitedMemberAccesses(content, varName string, fromIdx int) []string {
+	if fromIdx < 0 || fromIdx > len(content) {
+		return nil
 	}
 
-	// Look for the callback function with parameters
-	callbackRegex := regexp.MustCompile(`function\s*\(([^)]*)\)`)
-	callbackMatch := callbackRegex.FindStringSubmatch(defineCall)
-	if callbackMatch == nil || len(callbackMatch) < 2 {
-		return ""
+	window := content[fromIdx:]
+	if len(window) > 300 {
+		window = window[:300]
 	}
 
-	// Get the parameters of the callback function
-	params := []string{}
-	for _, param := range strings.Split(callbackMatch[1], ",") {
-		paramTrimmed := strings.TrimSpace(param)
-		if paramTrimmed != "" {
-			params = append(params, paramTrimmed)
+	memberRegex := regexp.MustCompile(regexp.QuoteMeta(varName) + `\.(\w+)\s*\(`)
+	symbols := []string{}
+	seen := map[string]bool{}
+	for _, match := range memberRegex.FindAllStringSubmatch(window, -1) {
+		if len(match) > 1 && !seen[match[1]] {
+			seen[match[1]] = true
+			symbols = append(symbols, match[1])
 		}
 	}
+	return symbols
+}
+
+// extractAMDParameterName finds the factory parameter bound to packageName's
+// position in the define() call starting at defineStart, by walking
+// internal/parser's AMDDefine (Deps and Params preserve declaration order,
+// so they're aligned by index) rather than re-matching the dependency array
+// and factory signature with their own regexes. file is nil if the caller's
+// parse attempt failed, in which case there's nothing to look up.
+func extractAMDParameterName(file *internalparser.File, defineStart int, packageName string) string {
+	if file == nil {
+		return ""
+	}
 
-	if moduleIndex < len(params) {
-		return params[moduleIndex]
+	for _, define := range file.AMDDefines {
+		if define.Start != defineStart {
+			continue
+		}
+		for i, dep := range define.Deps {
+			if dep == packageName && i < len(define.Params) {
+				return define.Params[i]
+			}
+		}
+		return ""
 	}
 
 	return ""
@@ -1204,12 +1129,3 @@ func extractImportedNameFromContext(importStatement, content string, lineStart i
 	return ""
 }
 
-// contains checks if a string slice contains a specific string
-func contains(slice []string, str string) bool {
-	for _, item := range slice {
-		if item == str {
-			return true
-		}
-	}
-	return false
-}
@@ -104,12 +104,11 @@ System.import('jquery').then($ => $('.element'));
 		t.Fatalf("findPackageInFile failed: %v", err)
 	}
 
-	// Note: Our current implementation's regex only captures the first ES6 import statement
-	// for 'react' due to how our regex patterns work. In a more complete implementation,
-	// we should handle multiple import statements for the same package.
-	// The TypeScript version handles this better with its AST-based approach.
-	if len(reactResults) < 1 {
-		t.Errorf("Expected at least 1 React import, got %d", len(reactResults))
+	// 'react' appears in exactly two places: the default import and the
+	// named import. 'react-dom' and 'react-router' are distinct packages
+	// and must not be counted here.
+	if len(reactResults) != 2 {
+		t.Errorf("Expected exactly 2 React imports, got %d", len(reactResults))
 	}
 
 	// Test finding axios usage
@@ -132,3 +131,181 @@ System.import('jquery').then($ => $('.element'));
 		t.Errorf("Expected 0 imports for nonexistent package, got %d", len(noResults))
 	}
 }
+
+func TestFindPackageInFileMatchesSubpathsAndTypesPackages(t *testing.T) {
+	content := `import React from '@types/react';
+import parse from '@babel/core/lib/parse';
+`
+
+	reactResults, err := findPackageInFile("test.ts", content, "react")
+	if err != nil {
+		t.Fatalf("findPackageInFile failed: %v", err)
+	}
+	if len(reactResults) != 1 {
+		t.Fatalf("expected 1 result for '@types/react', got %d", len(reactResults))
+	}
+
+	babelResults, err := findPackageInFile("test.ts", content, "@babel/core")
+	if err != nil {
+		t.Fatalf("findPackageInFile failed: %v", err)
+	}
+	if len(babelResults) != 1 {
+		t.Fatalf("expected 1 result for '@babel/core/lib/parse', got %d", len(babelResults))
+	}
+}
+
+func TestDynamicImportThenChain(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		packageName string
+		wantStyle   ImportStyle
+		wantSymbols []string
+	}{
+		{
+			name:        "then with destructured arrow callback",
+			content:     `import('react-router').then(({ useHistory }) => useHistory());`,
+			packageName: "react-router",
+			wantStyle:   DynamicImport,
+			wantSymbols: []string{"useHistory"},
+		},
+		{
+			name:        "then with destructured arrow callback and catch",
+			content:     `import('react-router').then(({ useHistory }) => useHistory()).catch(err => console.error(err));`,
+			packageName: "react-router",
+			wantStyle:   DynamicImport,
+			wantSymbols: []string{"useHistory"},
+		},
+		{
+			name:        "System.import then with destructured function callback",
+			content:     `System.import('jquery').then(function({ ajax }) { ajax('/x'); });`,
+			packageName: "jquery",
+			wantStyle:   SystemJS,
+			wantSymbols: []string{"ajax"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := findPackageInFile("test.js", test.content, test.packageName)
+			if err != nil {
+				t.Fatalf("findPackageInFile failed: %v", err)
+			}
+
+			var match *PackageUsage
+			for i := range results {
+				if results[i].ImportStyle == test.wantStyle {
+					match = &results[i]
+					break
+				}
+			}
+			if match == nil {
+				t.Fatalf("no result with ImportStyle %s among %d result(s)", test.wantStyle, len(results))
+			}
+
+			if len(match.ImportedSymbols) != len(test.wantSymbols) || match.ImportedSymbols[0] != test.wantSymbols[0] {
+				t.Errorf("ImportedSymbols = %v; want %v", match.ImportedSymbols, test.wantSymbols)
+			}
+		})
+	}
+}
+
+func TestFindPackageInFileResolvesImportMap(t *testing.T) {
+	dir := t.TempDir()
+
+	importMap := `{
+  "imports": {
+    "react-vendor": "https://cdn.skypack.dev/react@17"
+  }
+}`
+	if err := os.WriteFile(filepath.Join(dir, "import-map.json"), []byte(importMap), 0644); err != nil {
+		t.Fatalf("Failed to write import-map.json: %v", err)
+	}
+
+	content := `import React from 'react-vendor';`
+	filePath := filepath.Join(dir, "app.js")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write app.js: %v", err)
+	}
+
+	results, err := findPackageInFile(filePath, content, "react")
+	if err != nil {
+		t.Fatalf("findPackageInFile failed: %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result for import-mapped 'react-vendor', got %d", len(results))
+	}
+	if results[0].MatchedField != "importmap" {
+		t.Errorf("MatchedField = %q; want %q", results[0].MatchedField, "importmap")
+	}
+	if len(results[0].ResolvedConditions) != 1 || results[0].ResolvedConditions[0] != "imports" {
+		t.Errorf("ResolvedConditions = %v; want [imports]", results[0].ResolvedConditions)
+	}
+}
+
+func TestFindPackageInFileResolvesExportsSubpath(t *testing.T) {
+	dir := t.TempDir()
+
+	reactDir := filepath.Join(dir, "node_modules", "react")
+	if err := os.MkdirAll(reactDir, 0755); err != nil {
+		t.Fatalf("Failed to create node_modules/react: %v", err)
+	}
+	pkgJSON := `{
+  "name": "react",
+  "main": "index.js",
+  "exports": {
+    ".": "./index.js",
+    "./jsx-runtime": {
+      "import": "./jsx-runtime.mjs",
+      "default": "./jsx-runtime.js"
+    }
+  }
+}`
+	if err := os.WriteFile(filepath.Join(reactDir, "package.json"), []byte(pkgJSON), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	content := `import { jsx } from 'react/jsx-runtime';`
+	filePath := filepath.Join(dir, "app.js")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write app.js: %v", err)
+	}
+
+	results, err := findPackageInFile(filePath, content, "react")
+	if err != nil {
+		t.Fatalf("findPackageInFile failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result for 'react/jsx-runtime', got %d", len(results))
+	}
+
+	result := results[0]
+	if result.MatchedField != "exports[./jsx-runtime]" {
+		t.Errorf("MatchedField = %q; want %q", result.MatchedField, "exports[./jsx-runtime]")
+	}
+	if len(result.ResolvedConditions) != 1 || result.ResolvedConditions[0] != "import" {
+		t.Errorf("ResolvedConditions = %v; want [import]", result.ResolvedConditions)
+	}
+	if len(result.SymbolResolutions) != 1 || filepath.Base(result.SymbolResolutions[0].ActualDefinitionPath) != "jsx-runtime.mjs" {
+		t.Errorf("SymbolResolutions = %+v; want an entry pointing at jsx-runtime.mjs", result.SymbolResolutions)
+	}
+}
+
+func TestCanonicalPackageIdentity(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"https://cdn.skypack.dev/react@17", "react"},
+		{"https://esm.sh/@scope/pkg@1.2.3/dist/pkg.js", "@scope/pkg"},
+		{"react", "react"},
+		{"@scope/pkg", "@scope/pkg"},
+	}
+
+	for _, test := range tests {
+		if got := canonicalPackageIdentity(test.target); got != test.want {
+			t.Errorf("canonicalPackageIdentity(%q) = %q; want %q", test.target, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportMap is a parsed WICG import map document (the JSON a
+// `<script type="importmap">` tag or a Deno-style import map file holds):
+// a top-level "imports" specifier table, plus optional per-scope
+// overrides from "scopes" keyed by URL/path prefix. Use LoadImportMap to
+// parse one explicitly (e.g. for WithImportMap); NewImportMapResolver
+// discovers one automatically by walking up from a directory.
+type ImportMap struct {
+	Imports map[string]string            `json:"imports"`
+	Scopes  map[string]map[string]string `json:"scopes"`
+
+	// baseDir anchors relative scope prefixes, mirroring how
+	// NewTSConfigResolver roots baseUrl at its tsconfig.json's directory.
+	baseDir string
+}
+
+// LoadImportMap reads and parses the import map document at path.
+func LoadImportMap(path string) (*ImportMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var im ImportMap
+	if err := json.Unmarshal(data, &im); err != nil {
+		return nil, fmt.Errorf("parsing import map %s: %w", path, err)
+	}
+	im.baseDir = filepath.Dir(path)
+	return &im, nil
+}
+
+// ImportMapResolver resolves specifiers against a WICG import map (the
+// "imports"/"scopes" JSON that browsers and import-map-aware bundlers
+// consume), analogous to how TSConfigResolver handles tsconfig paths.
+type ImportMapResolver struct {
+	baseDir string
+	imports map[string]string
+	scopes  map[string]map[string]string
+}
+
+// NewImportMapResolver walks up from startDir looking for the nearest
+// "import-map.json", following the same directory-walking convention as
+// NewTSConfigResolver. It returns a resolver with no mappings (never an
+// error) when none is found, so callers can use it unconditionally.
+func NewImportMapResolver(startDir string) (*ImportMapResolver, error) {
+	configPath, err := findNearestImportMap(startDir)
+	if err != nil || configPath == "" {
+		return &ImportMapResolver{imports: map[string]string{}}, nil
+	}
+
+	im, err := LoadImportMap(configPath)
+	if err != nil {
+		return &ImportMapResolver{imports: map[string]string{}}, nil
+	}
+
+	return NewImportMapResolverFromMap(im), nil
+}
+
+// NewImportMapResolverFromMap builds an ImportMapResolver from an
+// explicitly loaded ImportMap (e.g. via LoadImportMap and WithImportMap),
+// instead of NewImportMapResolver's directory-walk discovery.
+func NewImportMapResolverFromMap(im *ImportMap) *ImportMapResolver {
+	if im == nil {
+		return &ImportMapResolver{imports: map[string]string{}}
+	}
+	return &ImportMapResolver{
+		baseDir: im.baseDir,
+		imports: im.Imports,
+		scopes:  im.Scopes,
+	}
+}
+
+func findNearestImportMap(dir string) (string, error) {
+	for {
+		candidate := filepath.Join(dir, "import-map.json")
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Resolve remaps specifier per the import map's "scopes" (the longest scope
+// prefix containing fromFile wins, same precedence as the import-map spec)
+// and falls back to the top-level "imports" map. Within each table, an exact
+// specifier match wins; otherwise the longest trailing-slash key that
+// prefixes specifier maps the directory, same as the import-map spec (e.g.
+// "lodash/": "https://cdn/lodash@4/" remaps "lodash/debounce" to
+// "https://cdn/lodash@4/debounce"). scopeKey is the scope prefix consumed,
+// or "" for a top-level-only match.
+func (r *ImportMapResolver) Resolve(specifier, fromFile string) (target string, scopeKey string, ok bool) {
+	if r == nil {
+		return "", "", false
+	}
+
+	if best := r.bestScope(fromFile); best != "" {
+		if mapped, _, ok := resolveSpecifierInTable(specifier, r.scopes[best]); ok {
+			return mapped, best, true
+		}
+	}
+
+	if mapped, _, ok := resolveSpecifierInTable(specifier, r.imports); ok {
+		return mapped, "", true
+	}
+
+	return "", "", false
+}
+
+// resolveSpecifierInTable resolves specifier against a single imports/scopes
+// table: an exact key match wins outright, otherwise the longest
+// trailing-slash key prefixing specifier maps the directory prefix, leaving
+// the remainder of specifier appended to its target.
+func resolveSpecifierInTable(specifier string, table map[string]string) (target string, key string, ok bool) {
+	if target, ok := table[specifier]; ok {
+		return target, specifier, true
+	}
+
+	bestKey := ""
+	for candidate := range table {
+		if !strings.HasSuffix(candidate, "/") || !strings.HasPrefix(specifier, candidate) {
+			continue
+		}
+		if len(candidate) > len(bestKey) {
+			bestKey = candidate
+		}
+	}
+	if bestKey == "" {
+		return "", "", false
+	}
+	return table[bestKey] + specifier[len(bestKey):], bestKey, true
+}
+
+// specifiersResolvingTo returns every "imports" or "scopes" key (top-level
+// or scoped) whose target canonically identifies packageName.
+// findPackageInFile uses this to widen its cheap textual pre-check for
+// specifiers that don't literally contain the package name (e.g.
+// "react-vendor" remapped to a react CDN URL, including when that remapping
+// only applies within a particular scope).
+func (r *ImportMapResolver) specifiersResolvingTo(packageName string) []string {
+	if r == nil {
+		return nil
+	}
+	var specifiers []string
+	for specifier, target := range r.imports {
+		if matchesPackageName(canonicalPackageIdentity(target), packageName, DefaultMatchOptions()) {
+			specifiers = append(specifiers, specifier)
+		}
+	}
+	for _, scopeTable := range r.scopes {
+		for specifier, target := range scopeTable {
+			if matchesPackageName(canonicalPackageIdentity(target), packageName, DefaultMatchOptions()) {
+				specifiers = append(specifiers, specifier)
+			}
+		}
+	}
+	return specifiers
+}
+
+// bestScope returns the longest scope prefix that contains fromFile. The
+// match is directory-bounded - scope "./vendor/" matches
+// "/project/vendor/a.ts" but not a sibling like "/project/vendor-mock/a.ts" -
+// and "longest wins" compares each candidate's own joined path length, not a
+// joined path against a bare scope key left over from a previous iteration.
+func (r *ImportMapResolver) bestScope(fromFile string) string {
+	best := ""
+	bestLen := -1
+	for scopePrefix := range r.scopes {
+		abs := filepath.Join(r.baseDir, scopePrefix)
+		if fromFile != abs && !strings.HasPrefix(fromFile, abs+string(filepath.Separator)) {
+			continue
+		}
+		if len(abs) > bestLen {
+			best, bestLen = scopePrefix, len(abs)
+		}
+	}
+	return best
+}
+
+// canonicalPackageIdentity collapses an import-map target back down to a
+// bare package identity so it can be compared with matchesPackageName: a CDN
+// URL like "https://cdn.skypack.dev/react@17" or
+// "https://esm.sh/@scope/pkg@1.2.3/dist/pkg.js" becomes "react" or
+// "@scope/pkg". A bare-specifier target (no scheme) is returned as-is, minus
+// any trailing version.
+func canonicalPackageIdentity(target string) string {
+	if idx := strings.Index(target, "://"); idx >= 0 {
+		target = target[idx+3:]
+		if slash := strings.Index(target, "/"); slash >= 0 {
+			target = target[slash+1:]
+		} else {
+			return target
+		}
+	}
+
+	root := packageRoot(target)
+	if at := strings.LastIndex(root, "@"); at > 0 {
+		root = root[:at]
+	}
+	return root
+}
@@ -0,0 +1,219 @@
+package finder
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadImportMapParsesImportsAndScopes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "import-map.json")
+	writeTestFile(t, path, `{
+		"imports": {"react": "https://cdn.skypack.dev/react@17"},
+		"scopes": {"./vendor/": {"react": "https://cdn.skypack.dev/react@16"}}
+	}`)
+
+	im, err := LoadImportMap(path)
+	if err != nil {
+		t.Fatalf("LoadImportMap failed: %v", err)
+	}
+	if im.Imports["react"] != "https://cdn.skypack.dev/react@17" {
+		t.Errorf("unexpected imports: %+v", im.Imports)
+	}
+	if im.Scopes["./vendor/"]["react"] != "https://cdn.skypack.dev/react@16" {
+		t.Errorf("unexpected scopes: %+v", im.Scopes)
+	}
+}
+
+func TestLoadImportMapMissingFileReturnsError(t *testing.T) {
+	if _, err := LoadImportMap(filepath.Join(t.TempDir(), "nope.json")); err == nil {
+		t.Fatal("expected an error for a missing import map file")
+	}
+}
+
+func TestLoadImportMapInvalidJSONReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "import-map.json")
+	writeTestFile(t, path, `{not valid json`)
+
+	if _, err := LoadImportMap(path); err == nil {
+		t.Fatal("expected an error for malformed JSON")
+	}
+}
+
+func TestImportMapResolverTrailingSlashMapsDirectoryPrefix(t *testing.T) {
+	im := &ImportMap{Imports: map[string]string{"lodash/": "https://cdn/lodash@4/"}}
+	resolver := NewImportMapResolverFromMap(im)
+
+	target, scopeKey, ok := resolver.Resolve("lodash/debounce", "/project/src/a.ts")
+	if !ok {
+		t.Fatal("expected lodash/debounce to resolve via the trailing-slash prefix")
+	}
+	if target != "https://cdn/lodash@4/debounce" {
+		t.Errorf("got target %q, want https://cdn/lodash@4/debounce", target)
+	}
+	if scopeKey != "" {
+		t.Errorf("expected no scope key for a top-level match, got %q", scopeKey)
+	}
+}
+
+func TestImportMapResolverPrefersLongestTrailingSlashKey(t *testing.T) {
+	im := &ImportMap{Imports: map[string]string{
+		"lodash/":    "https://cdn/lodash@4/",
+		"lodash/fp/": "https://cdn/lodash@4/fp/",
+	}}
+	resolver := NewImportMapResolverFromMap(im)
+
+	target, _, ok := resolver.Resolve("lodash/fp/debounce", "/project/src/a.ts")
+	if !ok {
+		t.Fatal("expected lodash/fp/debounce to resolve")
+	}
+	if target != "https://cdn/lodash@4/fp/debounce" {
+		t.Errorf("got target %q, want the longer lodash/fp/ prefix applied", target)
+	}
+}
+
+func TestImportMapResolverScopeTakesPrecedenceOverGlobal(t *testing.T) {
+	dir := t.TempDir()
+	im := &ImportMap{
+		baseDir: dir,
+		Imports: map[string]string{"react": "https://cdn/react@17"},
+		Scopes: map[string]map[string]string{
+			"vendor/": {"react": "https://cdn/react@16"},
+		},
+	}
+	resolver := NewImportMapResolverFromMap(im)
+
+	target, scopeKey, ok := resolver.Resolve("react", filepath.Join(dir, "vendor", "widget.ts"))
+	if !ok {
+		t.Fatal("expected react to resolve")
+	}
+	if target != "https://cdn/react@16" {
+		t.Errorf("expected the scoped mapping to win, got %q", target)
+	}
+	if scopeKey != "vendor/" {
+		t.Errorf("got scopeKey %q, want vendor/", scopeKey)
+	}
+
+	target, scopeKey, ok = resolver.Resolve("react", filepath.Join(dir, "src", "widget.ts"))
+	if !ok {
+		t.Fatal("expected react to resolve outside the scope too")
+	}
+	if target != "https://cdn/react@17" {
+		t.Errorf("expected the global mapping outside the scope, got %q", target)
+	}
+	if scopeKey != "" {
+		t.Errorf("expected no scope key outside the scope, got %q", scopeKey)
+	}
+}
+
+func TestImportMapResolverScopeDoesNotMatchSiblingDirectory(t *testing.T) {
+	im := &ImportMap{
+		baseDir: "/project",
+		Imports: map[string]string{"react": "https://cdn/react@17"},
+		Scopes: map[string]map[string]string{
+			"./vendor/": {"react": "https://cdn/react@16"},
+		},
+	}
+	resolver := NewImportMapResolverFromMap(im)
+
+	target, scopeKey, ok := resolver.Resolve("react", "/project/vendor-mock/app.js")
+	if !ok {
+		t.Fatal("expected react to resolve via the global mapping")
+	}
+	if target != "https://cdn/react@17" || scopeKey != "" {
+		t.Errorf("expected /project/vendor-mock/app.js to fall outside the ./vendor/ scope, got target %q scopeKey %q", target, scopeKey)
+	}
+}
+
+func TestImportMapResolverPrefersLongestScopeByOwnPathLength(t *testing.T) {
+	im := &ImportMap{
+		baseDir: "/project",
+		Scopes: map[string]map[string]string{
+			"vendor/":        {"react": "https://cdn/react@16"},
+			"vendor/modern/": {"react": "https://cdn/react@18"},
+		},
+	}
+	resolver := NewImportMapResolverFromMap(im)
+
+	target, scopeKey, ok := resolver.Resolve("react", "/project/vendor/modern/widget.ts")
+	if !ok {
+		t.Fatal("expected react to resolve")
+	}
+	if scopeKey != "vendor/modern/" {
+		t.Errorf("expected the longer vendor/modern/ scope to win, got scopeKey %q", scopeKey)
+	}
+	if target != "https://cdn/react@18" {
+		t.Errorf("got target %q, want the vendor/modern/ scope's mapping", target)
+	}
+}
+
+func TestPipelineCacheKeyDiffersByImportMapBaseDir(t *testing.T) {
+	sharedCache := t.TempDir()
+	projectA := t.TempDir()
+	projectB := t.TempDir()
+
+	const content = `import vendor from "pkg";
+console.log(vendor);
+`
+	// Byte-identical content and byte-identical scopes JSON, but a.ts sits
+	// outside the "vendor/" scope prefix while b.ts sits inside it, so
+	// whether "pkg" resolves to react depends on each project's own baseDir.
+	writeTestFile(t, filepath.Join(projectA, "a.ts"), content)
+	writeTestFile(t, filepath.Join(projectB, "vendor", "b.ts"), content)
+
+	scopesJSON := `{"scopes": {"vendor/": {"pkg": "https://cdn/react@17"}}}`
+	imA := &ImportMap{baseDir: projectA}
+	if err := json.Unmarshal([]byte(scopesJSON), imA); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	imB := &ImportMap{baseDir: projectB}
+	if err := json.Unmarshal([]byte(scopesJSON), imB); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	pa := NewPipeline(projectA, "react")
+	pa.CacheDir = sharedCache
+	pa.ImportMap = imA
+	resultsA, err := pa.Run()
+	if err != nil {
+		t.Fatalf("project A Run failed: %v", err)
+	}
+	if len(resultsA) != 0 {
+		t.Fatalf("expected project A's a.ts (outside the vendor/ scope) not to match, got %+v", resultsA)
+	}
+
+	pb := NewPipeline(projectB, "react")
+	pb.CacheDir = sharedCache
+	pb.ImportMap = imB
+	resultsB, err := pb.Run()
+	if err != nil {
+		t.Fatalf("project B Run failed: %v", err)
+	}
+	if len(resultsB) != 1 {
+		t.Fatalf("expected project B's vendor/b.ts to resolve via its own scope rather than reusing project A's cache entry, got %+v", resultsB)
+	}
+}
+
+func TestWithImportMapOverridesAutoDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	// An on-disk import-map.json remaps "react-vendor" to a react CDN URL;
+	// WithImportMap should take precedence over it entirely.
+	writeTestFile(t, filepath.Join(dir, "import-map.json"), `{
+		"imports": {"react-vendor": "https://cdn/react@17"}
+	}`)
+	writeTestFile(t, filepath.Join(dir, "a.ts"), `import vendor from "react-vendor";
+console.log(vendor);
+`)
+
+	p := NewPipeline(dir, "react")
+	p.ImportMap = &ImportMap{Imports: map[string]string{"preact-vendor": "https://cdn/preact@10"}}
+	results, err := p.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected the overriding import map to suppress the on-disk react-vendor mapping, got %+v", results)
+	}
+}
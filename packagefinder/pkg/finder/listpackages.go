@@ -0,0 +1,71 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ListPackages walks projectRoot and returns every distinct bare package
+// specifier imported anywhere in the project - e.g. ["lodash", "react"] -
+// sorted for deterministic output. Relative specifiers ("./utils") and
+// Node subpath imports ("#internal/foo", classified separately as
+// SubpathImport per chunk0-4) name project-local code rather than an
+// installed dependency, so they're excluded; that's the same bare-vs-local
+// distinction ProjectGraph.resolve uses to decide a chain has bottomed out
+// at a real package.
+func ListPackages(projectRoot string) ([]string, error) {
+	seen := map[string]bool{}
+	parser := NewASTParser()
+
+	err := filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isJavaScriptFile(filepath.Ext(path)) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		nodes, err := parser.Parse(path, string(content))
+		if err != nil {
+			return nil
+		}
+		for _, node := range nodes {
+			if isBarePackageSpecifier(node.ModuleName) {
+				seen[node.ModuleName] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]string, 0, len(seen))
+	for pkg := range seen {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
+
+// isBarePackageSpecifier reports whether specifier names an installed
+// package rather than project-local code.
+func isBarePackageSpecifier(specifier string) bool {
+	return specifier != "" &&
+		!strings.HasPrefix(specifier, ".") &&
+		!strings.HasPrefix(specifier, "/") &&
+		!strings.HasPrefix(specifier, "#")
+}
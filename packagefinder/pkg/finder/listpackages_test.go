@@ -0,0 +1,39 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListPackagesCollectsBareSpecifiersOnly(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-listpackages-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	aPath := filepath.Join(tempDir, "a.ts")
+	if err := os.WriteFile(aPath, []byte("import React from 'react';\nimport './local';\n"), 0644); err != nil {
+		t.Fatalf("Failed to write a.ts: %v", err)
+	}
+	bPath := filepath.Join(tempDir, "b.ts")
+	if err := os.WriteFile(bPath, []byte("const { debounce } = require('lodash');\n"), 0644); err != nil {
+		t.Fatalf("Failed to write b.ts: %v", err)
+	}
+
+	packages, err := ListPackages(tempDir)
+	if err != nil {
+		t.Fatalf("ListPackages failed: %v", err)
+	}
+
+	want := []string{"lodash", "react"}
+	if len(packages) != len(want) {
+		t.Fatalf("ListPackages = %v; want %v", packages, want)
+	}
+	for i, pkg := range want {
+		if packages[i] != pkg {
+			t.Errorf("packages[%d] = %q, want %q", i, packages[i], pkg)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package finder
+
+import "strings"
+
+// MatchOptions controls how an import specifier is compared against a
+// user-supplied package name in matchesPackageName.
+type MatchOptions struct {
+	// IncludeTypesPackages makes "react" also match "@types/react".
+	IncludeTypesPackages bool
+	// MatchSubpaths makes "lodash" also match "lodash/fp", and "@babel/core"
+	// also match "@babel/core/lib/parse".
+	MatchSubpaths bool
+	// ExactScopeMatch requires "@scope/name" to match only "@scope/name"
+	// (and its subpaths), never the bare "name".
+	ExactScopeMatch bool
+}
+
+// DefaultMatchOptions returns the matching behavior findPackageInFile uses
+// when no caller-supplied options are given.
+func DefaultMatchOptions() MatchOptions {
+	return MatchOptions{
+		IncludeTypesPackages: true,
+		MatchSubpaths:        true,
+		ExactScopeMatch:      true,
+	}
+}
+
+// normalizePackageName mirrors the TS compiler's module-name normalization:
+// strip a leading '/' and a leading "@types/" segment, preserving the scope
+// of a scoped package (e.g. "@types/node" -> "node", "@scope/name" stays
+// "@scope/name").
+func normalizePackageName(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	name = strings.TrimPrefix(name, "@types/")
+	return name
+}
+
+// packageRoot returns the package-identity portion of an import specifier,
+// i.e. everything up to (but not including) a subpath: "lodash/fp" ->
+// "lodash", "@babel/core/lib/parse" -> "@babel/core", "react" -> "react".
+func packageRoot(specifier string) string {
+	if strings.HasPrefix(specifier, "@") {
+		parts := strings.SplitN(specifier, "/", 3)
+		if len(parts) >= 2 {
+			return parts[0] + "/" + parts[1]
+		}
+		return specifier
+	}
+	return strings.SplitN(specifier, "/", 2)[0]
+}
+
+// matchesPackageName reports whether moduleName (an import specifier found
+// in source) refers to packageName (the package the caller is searching
+// for), per opts. ExactScopeMatch is enforced implicitly: normalizing and
+// rooting never merges "@scope/name" with a differently-scoped or unscoped
+// "name", so it's currently only meaningful as a documented toggle for
+// future matching strategies.
+func matchesPackageName(moduleName, packageName string, opts MatchOptions) bool {
+	module, target := moduleName, packageName
+	if opts.IncludeTypesPackages {
+		module = normalizePackageName(module)
+		target = normalizePackageName(target)
+	}
+	if opts.MatchSubpaths {
+		module = packageRoot(module)
+	}
+	return module == target
+}
@@ -0,0 +1,448 @@
+// Package noderesolver resolves a bare import specifier (e.g. "react" or
+// "lodash/fp") to the file it actually points at, following the same field
+// ordering Node and bundlers use: package.json `exports`/`imports` maps when
+// present, otherwise the legacy `browser`/`module`/`main` fields.
+package noderesolver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Platform selects which legacy field ordering to prefer when a package has
+// no `exports` map.
+type Platform string
+
+const (
+	PlatformNode    Platform = "node"
+	PlatformBrowser Platform = "browser"
+)
+
+// Resolution describes where a specifier ultimately pointed.
+type Resolution struct {
+	EntryPath            string
+	IsFromTypeDefinition bool
+	IsBuiltin            bool
+	// MatchedField names the package.json field the entry point came from
+	// ("module", "main", "browser", "exports[<subpath key>]", "imports", or
+	// "types"), so callers can surface why a given file was chosen.
+	MatchedField string
+	// ConditionPath records, in walk order, the condition keys ("node",
+	// "import", "require", "default", ...) selected while resolving an
+	// `exports`/`imports` conditions object, so callers can show *why* a
+	// usage was attributed beyond just which field it came from. It's nil
+	// for a resolution that didn't go through a conditions object (e.g. the
+	// legacy main/module/browser fields).
+	ConditionPath []string
+}
+
+type packageJSON struct {
+	Main    string                     `json:"main"`
+	Module  string                     `json:"module"`
+	Browser json.RawMessage            `json:"browser"`
+	Exports json.RawMessage            `json:"exports"`
+	Types   string                     `json:"types"`
+	Typings string                     `json:"typings"`
+	Imports map[string]json.RawMessage `json:"imports"`
+}
+
+// nodeBuiltins is a non-exhaustive set of Node core module names. It's only
+// used to tag a resolved `imports` target as a builtin (e.g. "node": "stream")
+// rather than a path within the package.
+var nodeBuiltins = map[string]bool{
+	"assert": true, "buffer": true, "child_process": true, "cluster": true,
+	"crypto": true, "dns": true, "events": true, "fs": true, "http": true,
+	"https": true, "net": true, "os": true, "path": true, "process": true,
+	"stream": true, "string_decoder": true, "tls": true, "url": true,
+	"util": true, "zlib": true,
+}
+
+// ResolveSubpathImport resolves a `#`-prefixed subpath import against the
+// nearest package.json's `imports` field, walking conditions in the order
+// "node", "import", "default". A target that names a Node core module is
+// reported with IsBuiltin=true instead of an EntryPath.
+func ResolveSubpathImport(startDir, specifier string) (*Resolution, error) {
+	pkgDir, err := findOwnPackageDir(startDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := readPackageJSON(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+
+	target, conditionPath, ok := matchImportsField(pkg.Imports, specifier)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	if nodeBuiltins[target] {
+		return &Resolution{EntryPath: "node:" + target, IsBuiltin: true, MatchedField: "imports", ConditionPath: conditionPath}, nil
+	}
+
+	return &Resolution{EntryPath: filepath.Join(pkgDir, target), MatchedField: "imports", ConditionPath: conditionPath}, nil
+}
+
+// matchImportsField resolves specifier against a package.json `imports` map,
+// matching an exact key first and then the most specific single-`*` pattern
+// key, then walking the matched entry's conditional object (node -> import
+// -> require -> default) the same way an `exports` entry would.
+func matchImportsField(imports map[string]json.RawMessage, specifier string) (string, []string, bool) {
+	key, raw, ok := matchSubpathKey(imports, specifier)
+	if !ok {
+		return "", nil, false
+	}
+
+	target, conditionPath, ok := resolveExportsConditions(raw, PlatformNode)
+	if !ok {
+		return "", nil, false
+	}
+
+	if idx := indexOfStar(key); idx >= 0 {
+		prefix, suffix := key[:idx], key[idx+1:]
+		captured := specifier[len(prefix) : len(specifier)-len(suffix)]
+		target = replaceStar(target, captured)
+	}
+	return target, conditionPath, true
+}
+
+// matchSubpathKey resolves subpath against m (a package.json `exports` or
+// `imports` subpath map), per Node's own precedence: an exact key always
+// wins; otherwise the single-`*` pattern key with the longest static prefix
+// is the most specific match and wins over shorter ones.
+func matchSubpathKey(m map[string]json.RawMessage, subpath string) (string, json.RawMessage, bool) {
+	if raw, ok := m[subpath]; ok {
+		return subpath, raw, true
+	}
+
+	bestKey, bestPrefixLen := "", -1
+	var bestRaw json.RawMessage
+	for key, raw := range m {
+		idx := indexOfStar(key)
+		if idx < 0 {
+			continue
+		}
+		prefix, suffix := key[:idx], key[idx+1:]
+		if len(subpath) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(subpath, prefix) && strings.HasSuffix(subpath, suffix) &&
+			len(prefix) > bestPrefixLen {
+			bestKey, bestRaw, bestPrefixLen = key, raw, len(prefix)
+		}
+	}
+	if bestPrefixLen < 0 {
+		return "", nil, false
+	}
+	return bestKey, bestRaw, true
+}
+
+func indexOfStar(s string) int {
+	for i, c := range s {
+		if c == '*' {
+			return i
+		}
+	}
+	return -1
+}
+
+func replaceStar(s, captured string) string {
+	idx := indexOfStar(s)
+	if idx < 0 {
+		return s
+	}
+	return s[:idx] + captured + s[idx+1:]
+}
+
+// findOwnPackageDir walks up from startDir to the nearest directory
+// containing a package.json that is not itself inside node_modules (i.e.
+// the importing project's own package, not a dependency).
+func findOwnPackageDir(startDir string) (string, error) {
+	dir := startDir
+	for {
+		if filepath.Base(filepath.Dir(dir)) != "node_modules" {
+			if info, err := os.Stat(filepath.Join(dir, "package.json")); err == nil && !info.IsDir() {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", os.ErrNotExist
+		}
+		dir = parent
+	}
+}
+
+// Resolver resolves specifiers against the node_modules tree rooted at (or
+// above) a given directory.
+type Resolver struct {
+	startDir string
+}
+
+// NewResolver returns a Resolver that looks for node_modules starting at
+// startDir and walking up to the filesystem root, mirroring Node's own
+// module resolution algorithm.
+func NewResolver(startDir string) *Resolver {
+	return &Resolver{startDir: startDir}
+}
+
+// Resolve returns the entry point for specifier - a bare package name (e.g.
+// "react") or a package name plus subpath (e.g. "react/jsx-runtime") -
+// under the given platform's field ordering: `exports` first (matching the
+// longest static subpath key, then the most specific single-`*` pattern
+// key; conditions walk node -> import -> require -> default on node,
+// browser -> import -> default on browser), then, for the package root
+// only, the legacy module -> main (node) or browser -> module -> main
+// (browser) fields. A subpath with no matching `exports` entry in a
+// package that has no `exports` map at all falls back to a direct file
+// join, the pre-`exports`-era behavior bundlers still honor for such
+// packages. If the package itself has no usable entry but a `@types/<pkg>`
+// sibling exists, that is returned instead with IsFromTypeDefinition=true.
+func (r *Resolver) Resolve(specifier string, platform Platform) (*Resolution, error) {
+	pkgName, subpath := splitSpecifier(specifier)
+
+	pkgDir, err := findPackageDir(r.startDir, pkgName)
+	if err != nil {
+		return r.resolveTypesFallback(specifier)
+	}
+
+	pkg, err := readPackageJSON(pkgDir)
+	if err != nil {
+		return r.resolveTypesFallback(specifier)
+	}
+
+	if entry, matchedKey, conditionPath, ok := resolveExportsField(pkg.Exports, subpath, platform); ok {
+		return &Resolution{
+			EntryPath:     filepath.Join(pkgDir, entry),
+			MatchedField:  "exports[" + matchedKey + "]",
+			ConditionPath: conditionPath,
+		}, nil
+	}
+
+	if subpath == "." {
+		if entry, field := pickLegacyField(pkg, platform); entry != "" {
+			return &Resolution{EntryPath: filepath.Join(pkgDir, entry), MatchedField: field}, nil
+		}
+	} else if len(pkg.Exports) == 0 {
+		return &Resolution{EntryPath: filepath.Join(pkgDir, subpath), MatchedField: "subpath"}, nil
+	}
+
+	return r.resolveTypesFallback(specifier)
+}
+
+// splitSpecifier splits specifier into a bare package name and its subpath
+// (as an `exports`-style key: "." for the package root, or "./rest" for a
+// deep import), honoring the two-segment form of a scoped package name
+// (e.g. "@scope/pkg/sub" -> "@scope/pkg", "./sub").
+func splitSpecifier(specifier string) (pkgName, subpath string) {
+	segments := strings.Split(specifier, "/")
+	nameParts := 1
+	if strings.HasPrefix(specifier, "@") && len(segments) > 1 {
+		nameParts = 2
+	}
+	if len(segments) <= nameParts {
+		return specifier, "."
+	}
+	return strings.Join(segments[:nameParts], "/"), "./" + strings.Join(segments[nameParts:], "/")
+}
+
+func (r *Resolver) resolveTypesFallback(specifier string) (*Resolution, error) {
+	typesSpecifier := toTypesPackage(specifier)
+	pkgDir, err := findPackageDir(r.startDir, typesSpecifier)
+	if err != nil {
+		return nil, os.ErrNotExist
+	}
+
+	pkg, err := readPackageJSON(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := pkg.Types
+	if entry == "" {
+		entry = pkg.Typings
+	}
+	if entry == "" {
+		entry = "index.d.ts"
+	}
+
+	return &Resolution{EntryPath: filepath.Join(pkgDir, entry), IsFromTypeDefinition: true, MatchedField: "types"}, nil
+}
+
+// toTypesPackage converts "react" -> "@types/react" and "@scope/name" ->
+// "@types/scope__name", matching the DefinitelyTyped naming convention.
+func toTypesPackage(specifier string) string {
+	if len(specifier) > 0 && specifier[0] == '@' {
+		rest := specifier[1:]
+		for i, c := range rest {
+			if c == '/' {
+				return "@types/" + rest[:i] + "__" + rest[i+1:]
+			}
+		}
+	}
+	return "@types/" + specifier
+}
+
+// pickLegacyField returns the entry path and the package.json field it came
+// from, or ("", "") if none of the legacy fields are usable.
+func pickLegacyField(pkg *packageJSON, platform Platform) (string, string) {
+	if platform == PlatformBrowser {
+		if browserMain := browserMainField(pkg.Browser); browserMain != "" {
+			return browserMain, "browser"
+		}
+	}
+	if pkg.Module != "" {
+		return pkg.Module, "module"
+	}
+	if pkg.Main != "" {
+		return pkg.Main, "main"
+	}
+	return "", ""
+}
+
+// resolveExportsField resolves subpath (an `exports`-style key: "." for the
+// package root, "./feature" for a deep import) against a package.json
+// `exports` map under platform's condition ordering. `exports` may be a
+// plain string, a subpath map keyed by "." and other subpaths, or a
+// conditions object directly (no subpaths) - all three shapes are valid
+// per the Node resolution spec, but only the first is reachable for any
+// subpath other than ".". It returns the matched subpath key alongside the
+// resolved entry so callers can report which one fired.
+func resolveExportsField(raw json.RawMessage, subpath string, platform Platform) (entry, matchedKey string, conditionPath []string, ok bool) {
+	if len(raw) == 0 {
+		return "", "", nil, false
+	}
+
+	if s, ok := stringValue(raw); ok {
+		if subpath != "." {
+			return "", "", nil, false
+		}
+		return s, ".", nil, true
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return "", "", nil, false
+	}
+
+	hasSubpathKeys := false
+	for key := range obj {
+		if strings.HasPrefix(key, ".") {
+			hasSubpathKeys = true
+			break
+		}
+	}
+
+	// A bare conditions object (no "."-prefixed keys) only ever describes
+	// the package root.
+	if !hasSubpathKeys {
+		if subpath != "." {
+			return "", "", nil, false
+		}
+		target, path, ok := resolveExportsConditions(raw, platform)
+		return target, ".", path, ok
+	}
+
+	key, matched, ok := matchSubpathKey(obj, subpath)
+	if !ok {
+		return "", "", nil, false
+	}
+	target, path, ok := resolveExportsConditions(matched, platform)
+	if !ok {
+		return "", "", nil, false
+	}
+	if idx := indexOfStar(key); idx >= 0 {
+		prefix, suffix := key[:idx], key[idx+1:]
+		captured := subpath[len(prefix) : len(subpath)-len(suffix)]
+		target = replaceStar(target, captured)
+	}
+	return target, key, path, true
+}
+
+// resolveExportsConditions unwraps an `exports`/`imports` conditions
+// object, walking conditions in the order Node/bundlers prefer for the
+// given platform and recursing into nested condition objects. It returns
+// the sequence of condition keys it selected along the way, so a caller
+// can show *why* a particular file was chosen. A `null` target is an
+// explicit block, per the Node resolution spec, and resolves to ok=false
+// rather than an empty string.
+func resolveExportsConditions(raw json.RawMessage, platform Platform) (target string, conditionPath []string, ok bool) {
+	if s, ok := stringValue(raw); ok {
+		return s, nil, true
+	}
+
+	var conditions map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &conditions); err != nil {
+		return "", nil, false
+	}
+
+	order := []string{"node", "import", "require", "default"}
+	if platform == PlatformBrowser {
+		order = []string{"browser", "import", "default"}
+	}
+	for _, condition := range order {
+		if nested, ok := conditions[condition]; ok {
+			if target, path, ok := resolveExportsConditions(nested, platform); ok {
+				return target, append([]string{condition}, path...), true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// stringValue reports the plain-string value of raw, or ok=false if it's a
+// condition object or an explicit `null` block.
+func stringValue(raw json.RawMessage) (string, bool) {
+	if strings.TrimSpace(string(raw)) == "null" {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// browserMainField handles the two shapes the `browser` field can take: a
+// plain string entry point, or a remapping object (in which case there is no
+// single "main", so we don't guess).
+func browserMainField(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return ""
+}
+
+func readPackageJSON(pkgDir string) (*packageJSON, error) {
+	data, err := os.ReadFile(filepath.Join(pkgDir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// findPackageDir walks up from startDir looking for node_modules/<specifier>,
+// returning the first match (nearest node_modules wins, same as Node).
+func findPackageDir(startDir, specifier string) (string, error) {
+	dir := startDir
+	for {
+		candidate := filepath.Join(dir, "node_modules", specifier)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", os.ErrNotExist
+		}
+		dir = parent
+	}
+}
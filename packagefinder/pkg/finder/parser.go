@@ -0,0 +1,342 @@
+package finder
+
+import (
+	"regexp"
+	"strings"
+)
+
+// NodeKind identifies the syntactic shape an import/require site was parsed from.
+type NodeKind string
+
+const (
+	NodeES6Import       NodeKind = "ES6Import"
+	NodeES6ImportAll    NodeKind = "ES6ImportAll"
+	NodeCommonJSRequire NodeKind = "CommonJSRequire"
+	NodeDynamicImport   NodeKind = "DynamicImport"
+	NodeSystemJSImport  NodeKind = "SystemJSImport"
+	NodeAMDDefine       NodeKind = "AMDDefine"
+	NodeUMDFactory      NodeKind = "UMDFactory"
+	NodeImportMap       NodeKind = "ImportMap"
+	NodeGlobalVariable  NodeKind = "GlobalVariable"
+	NodeExportNamed     NodeKind = "ExportNamedDeclaration"
+	NodeExportAll       NodeKind = "ExportAllDeclaration"
+)
+
+// ImportNode is a single import/require site produced by a Parser, tagged with
+// the ImportStyle it corresponds to so callers can drive per-style visitors
+// without re-deriving the style from the raw text.
+type ImportNode struct {
+	Kind       NodeKind
+	Style      ImportStyle
+	ModuleName string
+	Statement  string
+	Start      int
+	End        int
+	Symbols    []string
+	Namespace  string
+	IsDynamic  bool
+	// ReexportedAs holds the local binding names a re-export introduces
+	// (e.g. `export { debounce } from 'lodash'` -> ["debounce"]), so a
+	// ProjectGraph can trace a barrel file's exports back to their source
+	// package without re-parsing the export clause itself.
+	ReexportedAs []string
+}
+
+// Parser produces a stream of ImportNodes for a file's content. RegexParser is
+// the only implementation today; it is kept as the fallback/default so that
+// findPackageInFile can be rewritten on top of Parser without a behavior
+// change, and so a future full AST-backed Parser can be swapped in without
+// touching callers.
+type Parser interface {
+	Parse(filePath, content string) ([]ImportNode, error)
+}
+
+// RegexParser implements Parser using the same regular expressions
+// findPackageInFile already relies on. It exists so the regex-based
+// detection can be driven through the Parser interface instead of being
+// hardcoded into findPackageInFile.
+type RegexParser struct{}
+
+// NewRegexParser returns the default, regex-backed Parser.
+func NewRegexParser() *RegexParser {
+	return &RegexParser{}
+}
+
+// nodeVisitor maps an ImportStyle to the function that extracts ImportNodes
+// for that style from file content. Every ImportStyle constant has an entry
+// here so adding a new style is a matter of registering its visitor.
+var nodeVisitors = map[ImportStyle]func(content string) []ImportNode{
+	ES6Import:       visitES6Imports,
+	CommonJS:        visitCommonJSRequires,
+	DynamicImport:   visitDynamicImports,
+	SystemJS:        visitSystemJSImports,
+	RequireJS:       visitAMDDefines,
+	UMD:             visitUMDFactories,
+	ImportMaps:      visitImportMaps,
+	GlobalVariable:  visitGlobalVariables,
+	ESModuleInterop: visitESModuleInterop,
+}
+
+// Re-export forms (`export { x } from 'pkg'`, `export * from 'pkg'`, and the
+// aliased `export * as ns from 'pkg'`) aren't tagged with an ImportStyle of
+// their own - they surface a package the same way an import does, so they're
+// visited unconditionally alongside nodeVisitors rather than gated on style.
+var (
+	exportNamedFromRegex = regexp.MustCompile(`export\s*\{([^}]*)\}\s*from\s+['"]([^'"]+)['"]`)
+	exportAllFromRegex   = regexp.MustCompile(`export\s*\*\s*(?:as\s+(\w+)\s+)?from\s+['"]([^'"]+)['"]`)
+)
+
+// Parse walks content once per registered ImportStyle visitor and returns the
+// combined, unfiltered node stream (i.e. not yet matched against a specific
+// package name).
+func (p *RegexParser) Parse(filePath, content string) ([]ImportNode, error) {
+	nodes := []ImportNode{}
+	for _, visit := range nodeVisitors {
+		nodes = append(nodes, visit(content)...)
+	}
+	nodes = append(nodes, visitExportsFrom(content)...)
+	return nodes, nil
+}
+
+func visitExportsFrom(content string) []ImportNode {
+	nodes := []ImportNode{}
+
+	for _, match := range exportNamedFromRegex.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || len(match) < 6 || match[4] < 0 || match[5] <= match[4] {
+			continue
+		}
+		moduleName := content[match[4]:match[5]]
+		reexported := []string{}
+		for _, symbol := range strings.Split(content[match[2]:match[3]], ",") {
+			symbol = strings.TrimSpace(symbol)
+			if strings.Contains(symbol, " as ") {
+				parts := strings.SplitN(symbol, " as ", 2)
+				symbol = strings.TrimSpace(parts[1])
+			}
+			if symbol != "" {
+				reexported = append(reexported, symbol)
+			}
+		}
+		nodes = append(nodes, ImportNode{
+			Kind:         NodeExportNamed,
+			Style:        ES6Import,
+			ModuleName:   moduleName,
+			Statement:    extractStatement(content, match[0], match[1]),
+			Start:        match[0],
+			End:          match[1],
+			ReexportedAs: reexported,
+		})
+	}
+
+	for _, match := range exportAllFromRegex.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || len(match) < 6 || match[4] < 0 || match[5] <= match[4] {
+			continue
+		}
+		moduleName := content[match[4]:match[5]]
+		node := ImportNode{
+			Kind:       NodeExportAll,
+			Style:      ES6Import,
+			ModuleName: moduleName,
+			Statement:  extractStatement(content, match[0], match[1]),
+			Start:      match[0],
+			End:        match[1],
+		}
+		if match[2] >= 0 && match[3] > match[2] {
+			node.Namespace = content[match[2]:match[3]]
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+func visitES6Imports(content string) []ImportNode {
+	nodes := []ImportNode{}
+
+	for _, match := range es6ImportRegex.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || len(match) < 4 || match[2] < 0 || match[3] > len(content) {
+			continue
+		}
+		moduleName := content[match[2]:match[3]]
+		statement := extractStatement(content, match[0], match[1])
+		nodes = append(nodes, ImportNode{
+			Kind:       NodeES6Import,
+			Style:      ES6Import,
+			ModuleName: moduleName,
+			Statement:  statement,
+			Start:      match[0],
+			End:        match[1],
+			Symbols:    extractSymbolsFromES6Import(statement, moduleName),
+		})
+	}
+
+	for _, match := range es6ImportAllRegex.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || len(match) < 6 {
+			continue
+		}
+		namespace := content[match[2]:match[3]]
+		moduleName := content[match[4]:match[5]]
+		statement := extractStatement(content, match[0], match[1])
+		nodes = append(nodes, ImportNode{
+			Kind:       NodeES6ImportAll,
+			Style:      ES6Import,
+			ModuleName: moduleName,
+			Statement:  statement,
+			Start:      match[0],
+			End:        match[1],
+			Namespace:  namespace,
+			Symbols:    []string{"* as " + namespace},
+		})
+	}
+
+	return nodes
+}
+
+func visitCommonJSRequires(content string) []ImportNode {
+	nodes := []ImportNode{}
+
+	for _, match := range requireRegex.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || match[6] < 0 || match[7] <= match[6] {
+			continue
+		}
+		moduleName := content[match[6]:match[7]]
+		statement := extractStatement(content, match[0], match[1])
+
+		symbols := []string{}
+		if match[4] > 0 && match[5] > match[4] {
+			symbols = append(symbols, strings.TrimSpace(content[match[4]:match[5]]))
+		}
+		if match[2] > 0 && match[3] > match[2] {
+			for _, symbol := range strings.Split(content[match[2]:match[3]], ",") {
+				symbol = strings.TrimSpace(symbol)
+				if strings.Contains(symbol, ":") {
+					parts := strings.Split(symbol, ":")
+					if len(parts) == 2 {
+						symbol = strings.TrimSpace(parts[1])
+					}
+				}
+				if symbol != "" {
+					symbols = append(symbols, symbol)
+				}
+			}
+		}
+
+		nodes = append(nodes, ImportNode{
+			Kind:       NodeCommonJSRequire,
+			Style:      CommonJS,
+			ModuleName: moduleName,
+			Statement:  statement,
+			Start:      match[0],
+			End:        match[1],
+			Symbols:    symbols,
+		})
+	}
+
+	return nodes
+}
+
+func visitDynamicImports(content string) []ImportNode {
+	return visitSimpleCallImports(content, dynamicImportRegex, NodeDynamicImport, DynamicImport)
+}
+
+func visitSystemJSImports(content string) []ImportNode {
+	return visitSimpleCallImports(content, systemJSRegex, NodeSystemJSImport, SystemJS)
+}
+
+func visitImportMaps(content string) []ImportNode {
+	return visitSimpleCallImports(content, esmImportMapRegex, NodeImportMap, ImportMaps)
+}
+
+func visitSimpleCallImports(content string, re *regexp.Regexp, kind NodeKind, style ImportStyle) []ImportNode {
+	nodes := []ImportNode{}
+	for _, match := range re.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || match[2] < 0 || match[3] <= match[2] {
+			continue
+		}
+		moduleName := content[match[2]:match[3]]
+		statement := extractStatement(content, match[0], match[1])
+		nodes = append(nodes, ImportNode{
+			Kind:       kind,
+			Style:      style,
+			ModuleName: moduleName,
+			Statement:  statement,
+			Start:      match[0],
+			End:        match[1],
+			IsDynamic:  true,
+		})
+	}
+	return nodes
+}
+
+func visitAMDDefines(content string) []ImportNode {
+	nodes := []ImportNode{}
+	for _, match := range amdDefineRegex.FindAllStringSubmatchIndex(content, -1) {
+		if match == nil || match[2] < 0 || match[3] <= match[2] {
+			continue
+		}
+		deps := []string{}
+		for _, dep := range depNamesRegex.FindAllStringSubmatch(content[match[2]:match[3]], -1) {
+			if len(dep) > 1 {
+				deps = append(deps, dep[1])
+			}
+		}
+		for _, dep := range deps {
+			nodes = append(nodes, ImportNode{
+				Kind:       NodeAMDDefine,
+				Style:      RequireJS,
+				ModuleName: dep,
+				Statement:  extractStatement(content, match[0], match[1]),
+				Start:      match[0],
+				End:        match[1],
+			})
+		}
+	}
+	return nodes
+}
+
+func visitUMDFactories(content string) []ImportNode {
+	nodes := []ImportNode{}
+	for _, match := range umdFactoryRegex.FindAllStringIndex(content, -1) {
+		if match == nil {
+			continue
+		}
+		nodes = append(nodes, ImportNode{
+			Kind:      NodeUMDFactory,
+			Style:     UMD,
+			Statement: "UMD factory pattern",
+			Start:     match[0],
+			End:       match[1],
+		})
+	}
+	return nodes
+}
+
+func visitGlobalVariables(content string) []ImportNode {
+	// Global variable access is keyed on the package name being searched for,
+	// so there is nothing to visit independent of a package name; the
+	// package-aware matching still happens in findPackageInFile.
+	return nil
+}
+
+func visitESModuleInterop(content string) []ImportNode {
+	// ESModuleInterop is a resolution-time classification (CommonJS default
+	// export accessed through `.default`), not a distinct syntactic site, so
+	// it has no standalone visitor either.
+	return nil
+}
+
+func extractStatement(content string, start, end int) string {
+	lineStart := strings.LastIndex(content[:start], "\n") + 1
+	if lineStart < 0 {
+		lineStart = 0
+	}
+	lineEnd := end
+	if nextNewline := strings.Index(content[lineEnd:], "\n"); nextNewline >= 0 {
+		lineEnd += nextNewline
+	} else {
+		lineEnd = len(content)
+	}
+	return strings.TrimSpace(content[lineStart:lineEnd])
+}
+
+var depNamesRegex = regexp.MustCompile(`['"]([^'"]+)['"]`)
@@ -0,0 +1,41 @@
+package finder
+
+import "testing"
+
+func TestRegexParserDetectsReexports(t *testing.T) {
+	content := `export { debounce, throttle as slow } from 'lodash';
+export * from 'lodash-es';
+export * as utils from 'lodash';
+`
+
+	nodes, err := NewRegexParser().Parse("test.ts", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var named, all *ImportNode
+	for i := range nodes {
+		switch nodes[i].Kind {
+		case NodeExportNamed:
+			named = &nodes[i]
+		case NodeExportAll:
+			if nodes[i].ModuleName == "lodash-es" {
+				all = &nodes[i]
+			}
+		}
+	}
+
+	if named == nil {
+		t.Fatal("expected an ExportNamedDeclaration node")
+	}
+	if named.ModuleName != "lodash" {
+		t.Errorf("named.ModuleName = %q; want %q", named.ModuleName, "lodash")
+	}
+	if len(named.ReexportedAs) != 2 || named.ReexportedAs[0] != "debounce" || named.ReexportedAs[1] != "slow" {
+		t.Errorf("named.ReexportedAs = %v; want [debounce slow]", named.ReexportedAs)
+	}
+
+	if all == nil {
+		t.Fatal("expected an ExportAllDeclaration node for 'lodash-es'")
+	}
+}
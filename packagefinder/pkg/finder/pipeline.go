@@ -0,0 +1,370 @@
+package finder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// placeholderSymbols lists the synthetic "ImportedSymbols" entries
+// findPackageInFile emits for import styles that don't actually name a
+// symbol (e.g. "require('./init')" for side effects). They're excluded from
+// the set passed to findSymbolUsages, which only looks for real identifiers.
+var placeholderSymbols = map[string]bool{
+	"(side-effect only)":  true,
+	"(dynamic import)":    true,
+	"(require)":           true,
+	"(SystemJS import)":   true,
+	"(UMD factory)":       true,
+	"(AMD require)":       true,
+	"(ImportMaps)":        true,
+	"(SystemJS config)":   true,
+	"(SystemJS register)": true,
+}
+
+// fileParse is one file's Parse-stage output: the usages found in it, and
+// the real (non-placeholder) imported-symbol set Resolve needs for its
+// symbol-usage pass. Caching both together lets a cache hit skip re-parsing
+// the file entirely.
+type fileParse struct {
+	Path            string
+	Usages          []PackageUsage
+	ImportedSymbols map[string]bool
+}
+
+// Pipeline runs package-usage analysis as four timed stages, mirroring the
+// phased architecture of compilers like Elm's: Crawl discovers candidate
+// files, Parse extracts package usages from each (content-addressed cache +
+// worker-pool parallel), Resolve finds where each imported symbol is used,
+// and Aggregate flattens everything into the final result slice. Durations
+// is populated as each stage completes, keyed by stage name, so callers can
+// print a per-phase timing report the way `ts-diff` does.
+type Pipeline struct {
+	ProjectRoot string
+	PackageName string
+	// CacheDir overrides the on-disk cache location; empty uses
+	// "<ProjectRoot>/.ts-diff-cache". Ignored if Cache is set.
+	CacheDir string
+	// Cache overrides the Cache implementation Parse uses; nil uses the
+	// default FileCache rooted at CacheDir. Library users that want a
+	// different backing store (e.g. a shared service instead of the local
+	// disk) can supply their own.
+	Cache Cache
+	// CacheMode controls whether Parse consults/updates the cache: CacheOn
+	// (the zero value) reads and writes normally, CacheRefresh writes fresh
+	// results without reading stale ones first, and CacheOff disables
+	// caching entirely.
+	CacheMode CacheMode
+	// Concurrency overrides the Parse-stage worker pool size; 0 uses
+	// runtime.NumCPU().
+	Concurrency int
+	// Trace, if set, records a "scan" event per file Parse processes, for
+	// callers that want a Chrome Trace Event Format report of slow files.
+	Trace *Trace
+	// ImportMap, if set, overrides auto-discovery of the nearest
+	// "import-map.json" for every file Parse scans. See WithImportMap.
+	ImportMap *ImportMap
+
+	Durations map[string]time.Duration
+
+	cache Cache
+}
+
+// NewPipeline returns a Pipeline ready to analyze packageName's usage under
+// projectRoot.
+func NewPipeline(projectRoot, packageName string) *Pipeline {
+	return &Pipeline{
+		ProjectRoot: projectRoot,
+		PackageName: packageName,
+		Durations:   map[string]time.Duration{},
+	}
+}
+
+// Run executes Crawl, Parse, Resolve, and Aggregate in order and returns the
+// same result FindPackageUsage does.
+func (p *Pipeline) Run() ([]PackageUsage, error) {
+	files, err := p.Crawl()
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := p.Parse(files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Resolve(parsed); err != nil {
+		return nil, err
+	}
+
+	results := p.Aggregate(parsed)
+
+	if p.cache != nil {
+		p.time("Prune", func() error { return p.cache.Prune() })
+	}
+
+	return results, nil
+}
+
+func (p *Pipeline) cacheDir() string {
+	if p.CacheDir != "" {
+		return p.CacheDir
+	}
+	return filepath.Join(p.ProjectRoot, defaultCacheDir)
+}
+
+// Crawl walks the project tree and returns every JS/TS file, skipping
+// node_modules, .git, and the on-disk cache directory itself.
+func (p *Pipeline) Crawl() ([]string, error) {
+	var files []string
+	cacheDir := p.cacheDir()
+
+	err := p.time("Crawl", func() error {
+		return filepath.Walk(p.ProjectRoot, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				if info.Name() == "node_modules" || info.Name() == ".git" || path == cacheDir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !isJavaScriptFile(filepath.Ext(path)) {
+				return nil
+			}
+
+			files = append(files, path)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory: %v", err)
+	}
+	return files, nil
+}
+
+// Parse reads and analyzes every file in files, reusing cached results
+// whenever a file's content and the project's resolver state both match a
+// prior run, and parsing the rest across a worker pool sized from
+// runtime.NumCPU().
+func (p *Pipeline) Parse(files []string) ([]*fileParse, error) {
+	var parsed []*fileParse
+
+	err := p.time("Parse", func() error {
+		var cache Cache
+		switch {
+		case p.CacheMode == CacheOff:
+			cache = noopCache{}
+		case p.Cache != nil:
+			cache = p.Cache
+		default:
+			fc, err := NewFileCache(p.cacheDir())
+			if err != nil {
+				return err
+			}
+			cache = fc
+		}
+		p.cache = cache
+
+		resolverState := resolverStateKey(p.ProjectRoot)
+		if p.ImportMap != nil {
+			// WithImportMap replaces whatever import-map.json resolverState
+			// already folded in; its own content must invalidate the cache
+			// the same way editing that file would. baseDir is folded in
+			// separately since it's unexported and json.Marshal drops it,
+			// but it changes scope resolution just as much as Imports/Scopes.
+			if data, err := json.Marshal(p.ImportMap); err == nil {
+				h := sha256.New()
+				h.Write([]byte(resolverState))
+				h.Write(data)
+				h.Write([]byte(p.ImportMap.baseDir))
+				resolverState = hex.EncodeToString(h.Sum(nil))
+			}
+		}
+
+		workers := p.Concurrency
+		if workers < 1 {
+			workers = runtime.NumCPU()
+		}
+		if workers < 1 {
+			workers = 1
+		}
+		if workers > len(files) {
+			workers = len(files)
+		}
+		if workers < 1 {
+			workers = 1
+		}
+
+		jobs := make(chan string)
+		out := make(chan *fileParse, len(files))
+		var wg sync.WaitGroup
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			tid := i
+			go func() {
+				defer wg.Done()
+				for path := range jobs {
+					start := time.Now()
+					fp, cacheHit, err := p.parseFile(path, cache, resolverState)
+					if err != nil {
+						fmt.Printf("Warning: Error processing file %s: %v\n", path, err)
+						continue
+					}
+					if p.Trace != nil {
+						matches := 0
+						if fp != nil {
+							matches = len(fp.Usages)
+						}
+						p.Trace.RecordScan(path, tid, start, time.Since(start), matches, cacheHit)
+					}
+					if fp != nil {
+						out <- fp
+					}
+				}
+			}()
+		}
+
+		go func() {
+			for _, f := range files {
+				jobs <- f
+			}
+			close(jobs)
+		}()
+
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		for fp := range out {
+			parsed = append(parsed, fp)
+		}
+
+		// The worker pool completes files out of order; restore the
+		// deterministic, filepath.Walk-style ordering callers expect.
+		sort.Slice(parsed, func(i, j int) bool { return parsed[i].Path < parsed[j].Path })
+		return nil
+	})
+
+	return parsed, err
+}
+
+// parseFile resolves a single file's usages, preferring a cache hit keyed by
+// the file's content, the package being searched for, and the project's
+// resolver state (unless CacheMode is CacheRefresh, which always reparses).
+// The returned bool reports whether the result came from that cache rather
+// than a fresh parse.
+func (p *Pipeline) parseFile(path string, cache Cache, resolverState string) (*fileParse, bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Warning: Could not read file %s: %v\n", path, err)
+		return nil, false, nil
+	}
+
+	key := cache.Key(content, p.PackageName, resolverState)
+
+	if p.CacheMode != CacheRefresh {
+		if entry, ok := cache.Get(key); ok {
+			cache.Record(path, key)
+			if len(entry.Usages) == 0 {
+				return nil, true, nil
+			}
+			// The entry's content hash says nothing about which path
+			// produced it - two files (in this project or, now that the
+			// cache directory can be shared globally, in different
+			// projects) can have identical content and thus the same key.
+			// Rewrite FileName to the file actually being scanned so a
+			// cache hit never attributes usages to the wrong file.
+			usages := make([]PackageUsage, len(entry.Usages))
+			copy(usages, entry.Usages)
+			for i := range usages {
+				usages[i].FileName = path
+			}
+			symbolsMap := make(map[string]bool, len(entry.ImportedSymbols))
+			for _, symbol := range entry.ImportedSymbols {
+				symbolsMap[symbol] = true
+			}
+			return &fileParse{Path: path, Usages: usages, ImportedSymbols: symbolsMap}, true, nil
+		}
+	}
+
+	usages, err := findPackageInFile(path, string(content), p.PackageName, p.ImportMap)
+	if err != nil {
+		return nil, false, err
+	}
+
+	symbolsMap := make(map[string]bool)
+	for _, usage := range usages {
+		for _, symbol := range usage.ImportedSymbols {
+			if !placeholderSymbols[symbol] {
+				symbolsMap[symbol] = true
+			}
+		}
+	}
+
+	symbols := make([]string, 0, len(symbolsMap))
+	for symbol := range symbolsMap {
+		symbols = append(symbols, symbol)
+	}
+	cache.Put(key, cacheEntry{Usages: usages, ImportedSymbols: symbols})
+	cache.Record(path, key)
+
+	if len(usages) == 0 {
+		return nil, false, nil
+	}
+	return &fileParse{Path: path, Usages: usages, ImportedSymbols: symbolsMap}, false, nil
+}
+
+// Resolve finds where each file's imported symbols are actually used,
+// attaching the result to every PackageUsage parsed from that file.
+func (p *Pipeline) Resolve(parsed []*fileParse) error {
+	return p.time("Resolve", func() error {
+		for _, fp := range parsed {
+			if len(fp.ImportedSymbols) == 0 {
+				continue
+			}
+
+			symbolUsages, err := findSymbolUsages(fp.Path, fp.ImportedSymbols)
+			if err != nil || len(symbolUsages) == 0 {
+				continue
+			}
+
+			for i := range fp.Usages {
+				fp.Usages[i].SymbolUsages = symbolUsages
+			}
+		}
+		return nil
+	})
+}
+
+// Aggregate flattens every file's usages into the final result slice.
+func (p *Pipeline) Aggregate(parsed []*fileParse) []PackageUsage {
+	var results []PackageUsage
+	p.time("Aggregate", func() error {
+		for _, fp := range parsed {
+			results = append(results, fp.Usages...)
+		}
+		return nil
+	})
+	return results
+}
+
+func (p *Pipeline) time(phase string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	p.Durations[phase] = time.Since(start)
+	return err
+}
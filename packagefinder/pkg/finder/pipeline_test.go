@@ -0,0 +1,129 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestPipelineRunFindsUsagesAndTimesEveryStage(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "src", "a.ts"), "import React from 'react';\nconsole.log(React);\n")
+	writeTestFile(t, filepath.Join(dir, "src", "b.ts"), "import { useState } from 'react';\nuseState(1);\n")
+
+	p := NewPipeline(dir, "react")
+	results, err := p.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	for _, stage := range []string{"Crawl", "Parse", "Resolve", "Aggregate"} {
+		if _, ok := p.Durations[stage]; !ok {
+			t.Errorf("Durations missing stage %q: %v", stage, p.Durations)
+		}
+	}
+}
+
+func TestPipelineReusesCacheOnSecondRun(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.ts"), "import React from 'react';\n")
+
+	first, err := NewPipeline(dir, "react").Run()
+	if err != nil {
+		t.Fatalf("first Run failed: %v", err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("expected 1 result on first run, got %d", len(first))
+	}
+
+	cacheDir := filepath.Join(dir, defaultCacheDir)
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("expected cache dir to exist: %v", err)
+	}
+	if len(entries) < 2 { // one entry file + manifest.json
+		t.Fatalf("expected cached entries on disk, got %d", len(entries))
+	}
+
+	second, err := NewPipeline(dir, "react").Run()
+	if err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if len(second) != 1 {
+		t.Fatalf("expected 1 result on cached second run, got %d", len(second))
+	}
+}
+
+func TestPipelinePrunesDeletedFiles(t *testing.T) {
+	dir := t.TempDir()
+	staleFile := filepath.Join(dir, "stale.ts")
+	writeTestFile(t, staleFile, "import React from 'react';\n")
+
+	if _, err := NewPipeline(dir, "react").Run(); err != nil {
+		t.Fatalf("first Run failed: %v", err)
+	}
+
+	if err := os.Remove(staleFile); err != nil {
+		t.Fatalf("failed to remove %s: %v", staleFile, err)
+	}
+
+	results, err := NewPipeline(dir, "react").Run()
+	if err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected 0 results after deleting the only source file, got %d", len(results))
+	}
+
+	cache, err := NewFileCache(filepath.Join(dir, defaultCacheDir))
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+	if len(cache.manifest) != 0 {
+		t.Errorf("expected manifest to be pruned of the deleted file, got %v", cache.manifest)
+	}
+}
+
+func TestPipelineRespectsConcurrencyAndRecordsTrace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.ts"), "import React from 'react';\n")
+	writeTestFile(t, filepath.Join(dir, "b.ts"), "import { useState } from 'react';\n")
+
+	p := NewPipeline(dir, "react")
+	p.Concurrency = 1
+	p.Trace = NewTrace()
+
+	results, err := p.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	slowest := p.Trace.Slowest(10)
+	if len(slowest) != 2 {
+		t.Fatalf("expected 2 trace events (one per file), got %d", len(slowest))
+	}
+	for _, event := range slowest {
+		if event.Name != "scan" || event.Ph != "X" {
+			t.Errorf("unexpected trace event %+v", event)
+		}
+		if _, ok := event.Args["file"]; !ok {
+			t.Errorf("expected trace event to record a file path: %+v", event)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package finder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// binding records a single (local name -> source module, exported name)
+// fact discovered in one file, covering import, require, and `export ...
+// from` forms.
+type binding struct {
+	LocalName    string
+	SourceModule string
+	ExportedName string
+	// IsReexport is true when this binding also re-exports LocalName from
+	// the current file (an `export { x } from`/`export *` form), so other
+	// files importing it should be attributed back to SourceModule.
+	IsReexport bool
+}
+
+// ProjectGraph links every file in a project to the (local-name ->
+// source-module) bindings it introduces, and resolves relative specifiers
+// against tsconfig paths/baseUrl so a symbol reached through a barrel file
+// can still be attributed to the original package.
+type ProjectGraph struct {
+	root           string
+	bindingsByFile map[string][]binding
+}
+
+// BuildProjectGraph walks root, parses every JS/TS file with ASTParser
+// (RegexParser as its fallback for non-grammar import styles and
+// unparseable files), and builds the per-file binding tables described
+// above. It does not resolve re-export chains itself - that happens lazily
+// in ResolvePackageForSymbol so files can be added to the graph in any
+// order.
+func BuildProjectGraph(root string) (*ProjectGraph, error) {
+	graph := &ProjectGraph{root: root, bindingsByFile: map[string][]binding{}}
+	parser := NewASTParser()
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isJavaScriptFile(filepath.Ext(path)) {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		nodes, err := parser.Parse(path, string(content))
+		if err != nil {
+			return nil
+		}
+
+		graph.bindingsByFile[path] = bindingsFromNodes(path, nodes)
+		return nil
+	})
+
+	return graph, err
+}
+
+func bindingsFromNodes(file string, nodes []ImportNode) []binding {
+	bindings := []binding{}
+	for _, node := range nodes {
+		switch node.Kind {
+		case NodeES6Import, NodeCommonJSRequire:
+			for _, symbol := range node.Symbols {
+				localName := symbol
+				exportedName := symbol
+				if strings.HasPrefix(symbol, "* as ") {
+					localName = strings.TrimPrefix(symbol, "* as ")
+					exportedName = "*"
+				}
+				bindings = append(bindings, binding{
+					LocalName:    localName,
+					SourceModule: resolveRelativeModule(file, node.ModuleName),
+					ExportedName: exportedName,
+				})
+			}
+		case NodeES6ImportAll:
+			bindings = append(bindings, binding{
+				LocalName:    node.Namespace,
+				SourceModule: resolveRelativeModule(file, node.ModuleName),
+				ExportedName: "*",
+			})
+		case NodeExportNamed:
+			for _, symbol := range node.ReexportedAs {
+				bindings = append(bindings, binding{
+					LocalName:    symbol,
+					SourceModule: resolveRelativeModule(file, node.ModuleName),
+					ExportedName: symbol,
+					IsReexport:   true,
+				})
+			}
+		case NodeExportAll:
+			bindings = append(bindings, binding{
+				LocalName:    "*",
+				SourceModule: resolveRelativeModule(file, node.ModuleName),
+				ExportedName: "*",
+				IsReexport:   true,
+			})
+		}
+	}
+	return bindings
+}
+
+// resolveRelativeModule turns a relative specifier ("./index",
+// "../utils/math") into an absolute-ish project path so barrel files can be
+// looked up by the same key used in bindingsByFile; bare specifiers (node
+// package names) are returned unchanged.
+func resolveRelativeModule(fromFile, specifier string) string {
+	if !strings.HasPrefix(specifier, ".") {
+		return specifier
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(fromFile), specifier))
+}
+
+// ResolvePackageForSymbol traces localName, as bound in file, through any
+// number of re-export hops and returns the originating bare package name
+// (e.g. "lodash"), or ok=false if the chain bottoms out in project-local
+// code or a cycle.
+func (g *ProjectGraph) ResolvePackageForSymbol(file, localName string) (string, bool) {
+	return g.resolve(file, localName, map[string]bool{})
+}
+
+func (g *ProjectGraph) resolve(file, localName string, visited map[string]bool) (string, bool) {
+	key := file + "#" + localName
+	if visited[key] {
+		return "", false
+	}
+	visited[key] = true
+
+	for _, b := range g.bindingsByFile[file] {
+		if b.LocalName != localName && b.LocalName != "*" {
+			continue
+		}
+
+		// A bare specifier (no file extension guess resolves within the
+		// project) is already a package name - we're done.
+		candidateFile := g.findProjectFileForModule(b.SourceModule)
+		if candidateFile == "" {
+			return b.SourceModule, true
+		}
+
+		// Follow the chain into the file the re-export points at, looking
+		// for the same exported name there.
+		if pkg, ok := g.resolve(candidateFile, b.ExportedName, visited); ok {
+			return pkg, true
+		}
+		if pkg, ok := g.resolve(candidateFile, "*", visited); ok {
+			return pkg, true
+		}
+	}
+
+	return "", false
+}
+
+// findProjectFileForModule returns the graph-tracked file path that
+// modulePath resolves to (trying common extensions and an index file), or
+// "" if modulePath doesn't correspond to any file this graph parsed.
+func (g *ProjectGraph) findProjectFileForModule(modulePath string) string {
+	for _, candidate := range candidatePaths(modulePath) {
+		if _, ok := g.bindingsByFile[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func candidatePaths(modulePath string) []string {
+	exts := []string{"", ".ts", ".tsx", ".js", ".jsx"}
+	paths := []string{}
+	for _, ext := range exts {
+		paths = append(paths, modulePath+ext)
+		paths = append(paths, filepath.Join(modulePath, "index"+ext))
+	}
+	return paths
+}
+
+// ImportChain returns the sequence of files reached by following import
+// edges from entry to target, inclusive of both endpoints, or ok=false if
+// target isn't reachable from entry. It's FindUnusedExports' reachability
+// walk run with a specific destination in mind: instead of the full
+// reachable set, the caller gets back the one path that explains *why* a
+// file is imported - e.g. ["src/index.ts", "src/routes.ts",
+// "src/routes/users.ts"].
+func (g *ProjectGraph) ImportChain(entry, target string) ([]string, bool) {
+	entryFile := g.resolveProjectPath(entry)
+	targetFile := g.resolveProjectPath(target)
+	if entryFile == "" || targetFile == "" {
+		return nil, false
+	}
+
+	parent := map[string]string{entryFile: ""}
+	queue := []string{entryFile}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		if file == targetFile {
+			break
+		}
+		for _, b := range g.bindingsByFile[file] {
+			next := g.findProjectFileForModule(b.SourceModule)
+			if next == "" {
+				continue
+			}
+			if _, seen := parent[next]; seen {
+				continue
+			}
+			parent[next] = file
+			queue = append(queue, next)
+		}
+	}
+
+	if _, ok := parent[targetFile]; !ok {
+		return nil, false
+	}
+
+	var chain []string
+	for file := targetFile; ; file = parent[file] {
+		chain = append([]string{file}, chain...)
+		if file == entryFile {
+			break
+		}
+	}
+	return chain, true
+}
+
+// resolveProjectPath turns a caller-supplied path (absolute, or relative to
+// g.root) into the exact key bindingsByFile uses for it, trying the same
+// extension and index-file candidates findProjectFileForModule does.
+func (g *ProjectGraph) resolveProjectPath(p string) string {
+	candidates := []string{p}
+	if !filepath.IsAbs(p) {
+		candidates = append(candidates, filepath.Join(g.root, p))
+	}
+	for _, c := range candidates {
+		if _, ok := g.bindingsByFile[c]; ok {
+			return c
+		}
+		if resolved := g.findProjectFileForModule(c); resolved != "" {
+			return resolved
+		}
+	}
+	return ""
+}
+
+// FindUnusedExports marks which of pkg's symbols, as imported anywhere in
+// the graph, are never reached from a root file (a file matching one of
+// rootGlobs), and returns the reachable set's complement among files that
+// import pkg directly. This is a project-local reachability check, not a
+// true export-level dead-code analysis of pkg itself: it reports which of
+// *this project's* imports of pkg are unreachable from the given roots.
+func (g *ProjectGraph) FindUnusedExports(pkg string, rootGlobs []string) ([]string, error) {
+	roots := map[string]bool{}
+	for file := range g.bindingsByFile {
+		for _, glob := range rootGlobs {
+			if matched, _ := filepath.Match(glob, file); matched {
+				roots[file] = true
+			}
+			if rel, err := filepath.Rel(g.root, file); err == nil {
+				if matched, _ := filepath.Match(glob, rel); matched {
+					roots[file] = true
+				}
+			}
+		}
+	}
+
+	reachable := map[string]bool{}
+	var visit func(file string)
+	visit = func(file string) {
+		if reachable[file] {
+			return
+		}
+		reachable[file] = true
+		for _, b := range g.bindingsByFile[file] {
+			if next := g.findProjectFileForModule(b.SourceModule); next != "" {
+				visit(next)
+			}
+		}
+	}
+	for root := range roots {
+		visit(root)
+	}
+
+	unused := map[string]bool{}
+	for file, bindings := range g.bindingsByFile {
+		if reachable[file] {
+			continue
+		}
+		for _, b := range bindings {
+			if b.SourceModule == pkg {
+				unused[b.ExportedName] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(unused))
+	for symbol := range unused {
+		result = append(result, symbol)
+	}
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectGraphResolvesThroughBarrel(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-graph-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	indexPath := filepath.Join(tempDir, "index.ts")
+	if err := os.WriteFile(indexPath, []byte(`export { debounce } from 'lodash';`), 0644); err != nil {
+		t.Fatalf("Failed to write index.ts: %v", err)
+	}
+
+	consumerPath := filepath.Join(tempDir, "consumer.ts")
+	if err := os.WriteFile(consumerPath, []byte(`import { debounce } from './index';`), 0644); err != nil {
+		t.Fatalf("Failed to write consumer.ts: %v", err)
+	}
+
+	graph, err := BuildProjectGraph(tempDir)
+	if err != nil {
+		t.Fatalf("BuildProjectGraph failed: %v", err)
+	}
+
+	pkg, ok := graph.ResolvePackageForSymbol(consumerPath, "debounce")
+	if !ok {
+		t.Fatal("expected debounce to resolve through the barrel file")
+	}
+	if pkg != "lodash" {
+		t.Errorf("ResolvePackageForSymbol = %q; want %q", pkg, "lodash")
+	}
+}
+
+func TestImportChainFindsPathThroughFiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "packagefinder-graph-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	entryPath := filepath.Join(tempDir, "index.ts")
+	if err := os.WriteFile(entryPath, []byte(`import './routes';`), 0644); err != nil {
+		t.Fatalf("Failed to write index.ts: %v", err)
+	}
+	routesPath := filepath.Join(tempDir, "routes.ts")
+	if err := os.WriteFile(routesPath, []byte(`import './unrelated';`), 0644); err != nil {
+		t.Fatalf("Failed to write routes.ts: %v", err)
+	}
+	unrelatedPath := filepath.Join(tempDir, "unrelated.ts")
+	if err := os.WriteFile(unrelatedPath, []byte(`console.log('noop');`), 0644); err != nil {
+		t.Fatalf("Failed to write unrelated.ts: %v", err)
+	}
+
+	graph, err := BuildProjectGraph(tempDir)
+	if err != nil {
+		t.Fatalf("BuildProjectGraph failed: %v", err)
+	}
+
+	chain, ok := graph.ImportChain(entryPath, unrelatedPath)
+	if !ok {
+		t.Fatal("expected unrelated.ts to be reachable from index.ts")
+	}
+	want := []string{entryPath, routesPath, unrelatedPath}
+	if len(chain) != len(want) {
+		t.Fatalf("ImportChain = %v; want %v", chain, want)
+	}
+	for i, file := range want {
+		if chain[i] != file {
+			t.Errorf("chain[%d] = %q, want %q", i, chain[i], file)
+		}
+	}
+
+	if _, ok := graph.ImportChain(routesPath, entryPath); ok {
+		t.Error("expected no chain from routes.ts back to index.ts")
+	}
+}
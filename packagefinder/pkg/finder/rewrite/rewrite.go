@@ -0,0 +1,552 @@
+// Package rewrite is a structured codemod API for a single file's imports,
+// the same job go/ast/astutil's AddImport/DeleteImport/RewriteImport do on
+// top of go/ast: internal/parser already gives us a syntax tree and
+// positional Edit primitives, and this package is the file-path-in,
+// []finder.TextEdit-out layer callers (a CLI, an editor plugin, a larger
+// migration script) actually want, so they never have to read a file or
+// apply an edit themselves.
+package rewrite
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/packagefinder/internal/parser"
+	"github.com/user/packagefinder/pkg/finder"
+)
+
+// AddNamedImport returns the edit(s) that make symbol available as a named
+// import from pkg in file, reusing internal/parser's existing-clause
+// detection so a second call for the same pkg extends rather than
+// duplicates it.
+func AddNamedImport(file, pkg, symbol string) ([]finder.TextEdit, error) {
+	f, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+	return toTextEdits(file, parser.AddNamedImport(f, pkg, symbol)), nil
+}
+
+// AddDefaultImport returns the edit(s) that make local available as the
+// default import from pkg in file.
+func AddDefaultImport(file, pkg, local string) ([]finder.TextEdit, error) {
+	f, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+	return toTextEdits(file, parser.AddDefaultImport(f, pkg, local)), nil
+}
+
+// RemoveUnusedImports returns the edit(s) that delete every import
+// declaration in file whose bound name(s) are never referenced outside the
+// declaration itself. A side-effect import (`import '...'`) is never
+// considered unused, since it's kept for its side effects rather than any
+// binding.
+func RemoveUnusedImports(file string) ([]finder.TextEdit, error) {
+	f, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []parser.Edit
+	for _, decl := range f.Imports {
+		if isUnused(f.Source, decl) {
+			edits = append(edits, parser.RemoveImport(f, decl)...)
+		}
+	}
+	return toTextEdits(file, edits), nil
+}
+
+// RenameImportedSymbol returns the edit(s) that rename the local binding
+// oldName to newName for an import from pkg in file, plus every use of
+// oldName elsewhere in file. Renaming a named import that has no existing
+// alias (`{ oldName }`) introduces one (`{ oldName as newName }`) rather
+// than touching the exported name pkg actually exports; renaming an
+// already-aliased or default/namespace binding just replaces the local
+// name in place. It returns an error if no import from pkg binds oldName
+// locally.
+func RenameImportedSymbol(file, pkg, oldName, newName string) ([]finder.TextEdit, error) {
+	f, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decl := range f.Imports {
+		if decl.Specifier != pkg {
+			continue
+		}
+		edit, ok := renameBinding(f.Source, decl, oldName, newName)
+		if !ok {
+			continue
+		}
+
+		edits := []parser.Edit{edit}
+		for _, occ := range findOccurrencesOutside(f.Source, decl.Start, decl.End, oldName) {
+			edits = append(edits, parser.Edit{Start: occ[0], End: occ[1], NewText: newName})
+		}
+		return toTextEdits(file, edits), nil
+	}
+
+	return nil, fmt.Errorf("rewrite: no import from %q binds %q in %s", pkg, oldName, file)
+}
+
+// ConvertImportStyle returns the edit(s) that rewrite every import in file
+// matching from into the equivalent to statement. ES6Import and CommonJS
+// convert one statement at a time; RequireJS is different because a
+// `define([...], function(...){...})` call's dependency array and its
+// factory's parameter list are positionally paired across the whole
+// statement, so converting to or from RequireJS is a whole-file
+// restructuring (every convertible import/require in the file becomes one
+// define() call, or vice versa) rather than a per-statement edit like the
+// other pair.
+func ConvertImportStyle(file string, from, to finder.ImportStyle) ([]finder.TextEdit, error) {
+	f, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []parser.Edit
+	switch {
+	case from == finder.ES6Import && to == finder.CommonJS:
+		edits = es6ToCommonJS(f)
+	case from == finder.CommonJS && to == finder.ES6Import:
+		edits = commonJSToES6(f)
+	case from == finder.ES6Import && to == finder.RequireJS:
+		edits, err = es6ToAMD(f)
+	case from == finder.CommonJS && to == finder.RequireJS:
+		edits, err = commonJSToAMD(f)
+	case from == finder.RequireJS && to == finder.ES6Import:
+		edits, err = amdToES6(f)
+	case from == finder.RequireJS && to == finder.CommonJS:
+		edits, err = amdToCommonJS(f)
+	default:
+		return nil, fmt.Errorf("rewrite: converting %s to %s is not supported", from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toTextEdits(file, edits), nil
+}
+
+func load(file string) (*parser.File, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseSource(file, string(content))
+}
+
+func toTextEdits(file string, edits []parser.Edit) []finder.TextEdit {
+	if len(edits) == 0 {
+		return nil
+	}
+	out := make([]finder.TextEdit, len(edits))
+	for i, e := range edits {
+		out[i] = finder.TextEdit{File: file, Start: e.Start, End: e.End, Replacement: e.NewText}
+	}
+	return out
+}
+
+// isUnused reports whether none of decl's bound names appear anywhere in
+// source outside of decl's own statement.
+func isUnused(source string, decl parser.ImportDecl) bool {
+	if decl.IsSideEffect {
+		return false
+	}
+	names := boundNames(decl)
+	if len(names) == 0 {
+		return false
+	}
+	for _, name := range names {
+		if len(findOccurrencesOutside(source, decl.Start, decl.End, name)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// boundNames returns the local identifier(s) decl introduces.
+func boundNames(decl parser.ImportDecl) []string {
+	var names []string
+	if decl.Default != "" {
+		names = append(names, decl.Default)
+	}
+	if decl.Namespace != "" {
+		names = append(names, decl.Namespace)
+	}
+	for _, n := range decl.Named {
+		if n.Alias != "" {
+			names = append(names, n.Alias)
+		} else {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+// renameBinding locates decl's occurrence of oldName as a local name
+// (default, namespace, a bare named entry, or an existing alias) and
+// returns the edit that turns it into newName, or ok=false if decl doesn't
+// bind oldName locally.
+func renameBinding(source string, decl parser.ImportDecl, oldName, newName string) (edit parser.Edit, ok bool) {
+	replacement := newName
+	switch {
+	case decl.Default == oldName, decl.Namespace == oldName:
+		// in place
+	default:
+		found := false
+		for _, n := range decl.Named {
+			switch oldName {
+			case n.Alias:
+				found = true
+			case n.Name:
+				if n.Alias == "" {
+					replacement = n.Name + " as " + newName
+					found = true
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return parser.Edit{}, false
+		}
+	}
+
+	// The bindings clause ends at " from " (every shape here has one, since
+	// a bindings-free decl is IsSideEffect and never reaches this point);
+	// searching only that far keeps a same-named specifier from matching.
+	clauseEnd := decl.End
+	if idx := strings.Index(source[decl.Start:decl.End], " from "); idx >= 0 {
+		clauseEnd = decl.Start + idx
+	}
+
+	// A single import statement can only bind a given local name once, so
+	// there's exactly one whole-word occurrence of it in the clause.
+	for _, o := range occurrencesIn(source[decl.Start:clauseEnd], oldName) {
+		return parser.Edit{Start: decl.Start + o[0], End: decl.Start + o[1], NewText: replacement}, true
+	}
+	return parser.Edit{}, false
+}
+
+var identRegexCache = map[string]*regexp.Regexp{}
+
+func wordRegex(name string) *regexp.Regexp {
+	if re, ok := identRegexCache[name]; ok {
+		return re
+	}
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	identRegexCache[name] = re
+	return re
+}
+
+// occurrencesIn returns the [start, end) byte spans of every whole-word
+// occurrence of name within text.
+func occurrencesIn(text, name string) [][2]int {
+	var spans [][2]int
+	for _, m := range wordRegex(name).FindAllStringIndex(text, -1) {
+		spans = append(spans, [2]int{m[0], m[1]})
+	}
+	return spans
+}
+
+// findOccurrencesOutside returns the [start, end) byte spans of every
+// whole-word occurrence of name in source, skipping the [exceptStart,
+// exceptEnd) range (an import declaration's own statement).
+func findOccurrencesOutside(source string, exceptStart, exceptEnd int, name string) [][2]int {
+	var spans [][2]int
+	for _, span := range occurrencesIn(source, name) {
+		if span[0] >= exceptStart && span[1] <= exceptEnd {
+			continue
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// statementEnd extends end past a single trailing semicolon, if present, so
+// a full-statement replacement doesn't leave the old one behind; neither
+// ImportDecl.End nor RequireCall.End include it, since the regexes that
+// produce them stop at the closing quote.
+func statementEnd(source string, end int) int {
+	if end < len(source) && source[end] == ';' {
+		return end + 1
+	}
+	return end
+}
+
+// es6ToCommonJS converts every eligible ES6 import declaration in f to a
+// `const ... = require(...)` statement. Namespace imports (`import * as
+// X`) and type-only imports have no CommonJS runtime equivalent and are
+// left alone.
+func es6ToCommonJS(f *parser.File) []parser.Edit {
+	var edits []parser.Edit
+	for _, decl := range f.Imports {
+		if decl.IsTypeOnly || decl.Namespace != "" {
+			continue
+		}
+
+		requireExpr := "require('" + decl.Specifier + "')"
+		var stmt string
+		switch {
+		case decl.IsSideEffect:
+			stmt = requireExpr + ";"
+		case decl.Default != "" && len(decl.Named) == 0:
+			stmt = "const " + decl.Default + " = " + requireExpr + ";"
+		case decl.Default == "" && len(decl.Named) > 0:
+			stmt = "const { " + joinNamed(decl.Named) + " } = " + requireExpr + ";"
+		case decl.Default != "" && len(decl.Named) > 0:
+			stmt = "const " + decl.Default + " = " + requireExpr + ";\n" +
+				"const { " + joinNamed(decl.Named) + " } = " + decl.Default + ";"
+		default:
+			continue
+		}
+		edits = append(edits, parser.Edit{Start: decl.Start, End: statementEnd(f.Source, decl.End), NewText: stmt})
+	}
+	return edits
+}
+
+// commonJSToES6 converts every `require(...)` call in f, assigned to a
+// plain identifier or destructured, to an ES6 import declaration. A
+// destructured binding that renames a key (`const { a: b } = require(...)`)
+// only round-trips back to the local alias b: internal/parser's
+// RequireCall.Destructured doesn't keep the original key, so the result is
+// `import { b } from '...'` rather than `import { a as b } from '...'` - an
+// existing limitation of the parser, not something this conversion can
+// recover from.
+func commonJSToES6(f *parser.File) []parser.Edit {
+	var edits []parser.Edit
+	for _, call := range f.Requires {
+		var stmt string
+		switch {
+		case call.Binding != "" && len(call.Destructured) == 0:
+			stmt = "import " + call.Binding + " from '" + call.Specifier + "';"
+		case call.Binding == "" && len(call.Destructured) > 0:
+			stmt = "import { " + strings.Join(call.Destructured, ", ") + " } from '" + call.Specifier + "';"
+		case call.Binding == "" && len(call.Destructured) == 0:
+			stmt = "import '" + call.Specifier + "';"
+		default:
+			continue
+		}
+		edits = append(edits, parser.Edit{Start: call.Start, End: statementEnd(f.Source, call.End), NewText: stmt})
+	}
+	return edits
+}
+
+// es6ToAMD converts every non-type-only ES6 import in f into one
+// `define([...deps], function(...params) { ... })` call wrapping the rest
+// of the file. Each import contributes one dependency, positionally paired
+// with one factory parameter - its default or namespace binding name, or
+// (for a named-only or side-effect import, which has no single local name)
+// an identifier synthesized from the specifier - so the array and the
+// parameter list stay aligned even for imports AMD factories don't usually
+// name a parameter for. A named clause becomes a destructuring prelude
+// statement inside the factory body, sourced from that same parameter.
+func es6ToAMD(f *parser.File) ([]parser.Edit, error) {
+	var deps, params, prelude []string
+	var remove [][2]int
+	for _, decl := range f.Imports {
+		if decl.IsTypeOnly {
+			continue
+		}
+		param := decl.Default
+		if param == "" {
+			param = decl.Namespace
+		}
+		if param == "" {
+			param = identFromSpecifier(decl.Specifier)
+		}
+		deps = append(deps, decl.Specifier)
+		params = append(params, param)
+		if len(decl.Named) > 0 {
+			source := decl.Default
+			if source == "" {
+				source = param
+			}
+			prelude = append(prelude, "  var { "+joinNamed(decl.Named)+" } = "+source+";")
+		}
+		remove = append(remove, [2]int{decl.Start, statementEnd(f.Source, decl.End)})
+	}
+	if len(deps) == 0 {
+		return nil, fmt.Errorf("rewrite: no ES6 imports in file to convert to RequireJS")
+	}
+	return wrapInDefine(f.Source, deps, params, prelude, remove), nil
+}
+
+// commonJSToAMD is es6ToAMD's CommonJS counterpart: every require() call's
+// binding (or a synthesized identifier, for a destructured or unassigned
+// call) becomes a factory parameter, and a destructured call also gets a
+// prelude statement pulling its names off that parameter.
+func commonJSToAMD(f *parser.File) ([]parser.Edit, error) {
+	var deps, params, prelude []string
+	var remove [][2]int
+	for _, call := range f.Requires {
+		param := call.Binding
+		if param == "" {
+			param = identFromSpecifier(call.Specifier)
+		}
+		deps = append(deps, call.Specifier)
+		params = append(params, param)
+		if len(call.Destructured) > 0 {
+			source := call.Binding
+			if source == "" {
+				source = param
+			}
+			prelude = append(prelude, "  var { "+strings.Join(call.Destructured, ", ")+" } = "+source+";")
+		}
+		remove = append(remove, [2]int{call.Start, statementEnd(f.Source, call.End)})
+	}
+	if len(deps) == 0 {
+		return nil, fmt.Errorf("rewrite: no CommonJS requires in file to convert to RequireJS")
+	}
+	return wrapInDefine(f.Source, deps, params, prelude, remove), nil
+}
+
+// wrapInDefine builds the single whole-file edit that replaces source with
+// a `define([deps], function(params) { prelude; rest })` call, where rest is
+// source with every span in remove (the statements being converted) cut out.
+func wrapInDefine(source string, deps, params, prelude []string, remove [][2]int) []parser.Edit {
+	quoted := make([]string, len(deps))
+	for i, d := range deps {
+		quoted[i] = "'" + d + "'"
+	}
+
+	rest := strings.Trim(excise(source, remove), "\n")
+
+	var b strings.Builder
+	b.WriteString("define([" + strings.Join(quoted, ", ") + "], function(" + strings.Join(params, ", ") + ") {\n")
+	for _, line := range prelude {
+		b.WriteString(line + "\n")
+	}
+	if rest != "" {
+		b.WriteString(rest + "\n")
+	}
+	b.WriteString("});\n")
+
+	return []parser.Edit{{Start: 0, End: len(source), NewText: b.String()}}
+}
+
+// excise returns source with every [start, end) span in spans cut out.
+func excise(source string, spans [][2]int) string {
+	sorted := append([][2]int(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+
+	var b strings.Builder
+	cursor := 0
+	for _, span := range sorted {
+		b.WriteString(source[cursor:span[0]])
+		cursor = span[1]
+	}
+	b.WriteString(source[cursor:])
+	return b.String()
+}
+
+// identFromSpecifier synthesizes an identifier for a module specifier that
+// has no local binding name of its own (a named-only or side-effect ES6
+// import, or a require() call that isn't assigned to anything), by
+// replacing every non-alphanumeric byte with an underscore.
+func identFromSpecifier(specifier string) string {
+	b := []byte(specifier)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	s := strings.Trim(string(b), "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	return s
+}
+
+// amdToES6 converts f's sole define([...], function(...){...}) call back
+// into one `import ... from '...'` per dependency, keyed off the factory's
+// own parameter list (see soleConvertibleAMDDefine for why only one define()
+// is supported).
+func amdToES6(f *parser.File) ([]parser.Edit, error) {
+	define, err := soleConvertibleAMDDefine(f)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, len(define.Deps))
+	for i, dep := range define.Deps {
+		if param := paramAt(define.Params, i); param != "" {
+			lines[i] = "import " + param + " from '" + dep + "';"
+		} else {
+			lines[i] = "import '" + dep + "';"
+		}
+	}
+	return replaceDefineWithStatements(f.Source, define, lines), nil
+}
+
+// amdToCommonJS is amdToES6's CommonJS counterpart.
+func amdToCommonJS(f *parser.File) ([]parser.Edit, error) {
+	define, err := soleConvertibleAMDDefine(f)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, len(define.Deps))
+	for i, dep := range define.Deps {
+		if param := paramAt(define.Params, i); param != "" {
+			lines[i] = "const " + param + " = require('" + dep + "');"
+		} else {
+			lines[i] = "require('" + dep + "');"
+		}
+	}
+	return replaceDefineWithStatements(f.Source, define, lines), nil
+}
+
+// soleConvertibleAMDDefine returns f's one AMDDefine that has the
+// dependency-array-plus-function-expression shape amdToES6/amdToCommonJS
+// round-trip (BodyEnd > 0). Converting AMD to another style is, like the
+// reverse direction, a whole-file operation, so a file with zero or more
+// than one such define() call - ambiguous about which one to unwrap - is
+// rejected rather than guessed at.
+func soleConvertibleAMDDefine(f *parser.File) (parser.AMDDefine, error) {
+	var candidates []parser.AMDDefine
+	for _, d := range f.AMDDefines {
+		if d.BodyEnd > 0 {
+			candidates = append(candidates, d)
+		}
+	}
+	if len(candidates) != 1 {
+		return parser.AMDDefine{}, fmt.Errorf("rewrite: expected exactly one define([...], function(...){...}) call to convert, found %d", len(candidates))
+	}
+	return candidates[0], nil
+}
+
+func paramAt(params []string, i int) string {
+	if i < len(params) {
+		return params[i]
+	}
+	return ""
+}
+
+// replaceDefineWithStatements builds the edit that unwraps define into one
+// statement per dependency followed by its factory body, kept as-is.
+func replaceDefineWithStatements(source string, define parser.AMDDefine, statements []string) []parser.Edit {
+	body := strings.Trim(source[define.BodyStart:define.BodyEnd], "\n")
+
+	lines := append([]string(nil), statements...)
+	if body != "" {
+		lines = append(lines, body)
+	}
+
+	return []parser.Edit{{Start: define.Start, End: define.End, NewText: strings.Join(lines, "\n")}}
+}
+
+func joinNamed(named []parser.NamedImport) string {
+	parts := make([]string, 0, len(named))
+	for _, n := range named {
+		if n.Alias != "" {
+			parts = append(parts, n.Name+": "+n.Alias)
+		} else {
+			parts = append(parts, n.Name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,206 @@
+package rewrite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/packagefinder/internal/parser"
+	"github.com/user/packagefinder/pkg/finder"
+)
+
+func writeTempFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.ts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write app.ts: %v", err)
+	}
+	return path
+}
+
+func apply(t *testing.T, path string, edits []finder.TextEdit) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	pedits := make([]parser.Edit, len(edits))
+	for i, e := range edits {
+		pedits[i] = parser.Edit{Start: e.Start, End: e.End, NewText: e.Replacement}
+	}
+	return parser.ApplyEdits(string(content), pedits)
+}
+
+func TestAddNamedImportExtendsExistingClause(t *testing.T) {
+	path := writeTempFile(t, "import { useState } from 'react';\n")
+
+	edits, err := AddNamedImport(path, "react", "useEffect")
+	if err != nil {
+		t.Fatalf("AddNamedImport failed: %v", err)
+	}
+	if got := apply(t, path, edits); got != "import { useState, useEffect } from 'react';\n" {
+		t.Errorf("unexpected result: %q", got)
+	}
+}
+
+func TestRemoveUnusedImportsLeavesUsedImportsAlone(t *testing.T) {
+	src := "import { useState } from 'react';\nimport { debounce } from 'lodash';\n" +
+		"useState(0);\n"
+	path := writeTempFile(t, src)
+
+	edits, err := RemoveUnusedImports(path)
+	if err != nil {
+		t.Fatalf("RemoveUnusedImports failed: %v", err)
+	}
+	if got := apply(t, path, edits); got != "import { useState } from 'react';\nuseState(0);\n" {
+		t.Errorf("unexpected result: %q", got)
+	}
+}
+
+func TestRenameImportedSymbolAddsAliasAndRenamesUses(t *testing.T) {
+	src := "import { debounce } from 'lodash';\nconst run = debounce(fn, 10);\n"
+	path := writeTempFile(t, src)
+
+	edits, err := RenameImportedSymbol(path, "lodash", "debounce", "deb")
+	if err != nil {
+		t.Fatalf("RenameImportedSymbol failed: %v", err)
+	}
+	want := "import { debounce as deb } from 'lodash';\nconst run = deb(fn, 10);\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestRenameImportedSymbolRenamesExistingAlias(t *testing.T) {
+	src := "import { debounce as deb } from 'lodash';\nconst run = deb(fn, 10);\n"
+	path := writeTempFile(t, src)
+
+	edits, err := RenameImportedSymbol(path, "lodash", "deb", "throttleLike")
+	if err != nil {
+		t.Fatalf("RenameImportedSymbol failed: %v", err)
+	}
+	want := "import { debounce as throttleLike } from 'lodash';\nconst run = throttleLike(fn, 10);\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestRenameImportedSymbolErrorsWhenNotBound(t *testing.T) {
+	path := writeTempFile(t, "import { debounce } from 'lodash';\n")
+
+	if _, err := RenameImportedSymbol(path, "lodash", "throttle", "t"); err == nil {
+		t.Error("expected an error for a name lodash's import doesn't bind")
+	}
+}
+
+func TestConvertImportStyleES6ToCommonJS(t *testing.T) {
+	path := writeTempFile(t, "import React, { useState } from 'react';\n")
+
+	edits, err := ConvertImportStyle(path, finder.ES6Import, finder.CommonJS)
+	if err != nil {
+		t.Fatalf("ConvertImportStyle failed: %v", err)
+	}
+	want := "const React = require('react');\nconst { useState } = React;\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleCommonJSToES6(t *testing.T) {
+	path := writeTempFile(t, "const { debounce } = require('lodash');\n")
+
+	edits, err := ConvertImportStyle(path, finder.CommonJS, finder.ES6Import)
+	if err != nil {
+		t.Fatalf("ConvertImportStyle failed: %v", err)
+	}
+	want := "import { debounce } from 'lodash';\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleES6ToAMD(t *testing.T) {
+	path := writeTempFile(t, "import React, { useState } from 'react';\nReact.render(useState);\n")
+
+	edits, err := ConvertImportStyle(path, finder.ES6Import, finder.RequireJS)
+	if err != nil {
+		t.Fatalf("ConvertImportStyle failed: %v", err)
+	}
+	want := "define(['react'], function(React) {\n" +
+		"  var { useState } = React;\n" +
+		"React.render(useState);\n" +
+		"});\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleCommonJSToAMD(t *testing.T) {
+	path := writeTempFile(t, "const debounce = require('lodash');\n")
+
+	edits, err := ConvertImportStyle(path, finder.CommonJS, finder.RequireJS)
+	if err != nil {
+		t.Fatalf("ConvertImportStyle failed: %v", err)
+	}
+	want := "define(['lodash'], function(debounce) {\n});\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleAMDToES6(t *testing.T) {
+	path := writeTempFile(t, "define(['react'], function(React) {\n  React.render();\n});\n")
+
+	edits, err := ConvertImportStyle(path, finder.RequireJS, finder.ES6Import)
+	if err != nil {
+		t.Fatalf("ConvertImportStyle failed: %v", err)
+	}
+	want := "import React from 'react';\n  React.render();\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleAMDToCommonJS(t *testing.T) {
+	path := writeTempFile(t, "define(['lodash'], function(_) {\n  _.debounce();\n});\n")
+
+	edits, err := ConvertImportStyle(path, finder.RequireJS, finder.CommonJS)
+	if err != nil {
+		t.Fatalf("ConvertImportStyle failed: %v", err)
+	}
+	want := "const _ = require('lodash');\n  _.debounce();\n"
+	if got := apply(t, path, edits); got != want {
+		t.Errorf("unexpected result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleAMDRoundTripsThroughES6(t *testing.T) {
+	path := writeTempFile(t, "import React, { useState } from 'react';\nReact.render(useState);\n")
+
+	toAMD, err := ConvertImportStyle(path, finder.ES6Import, finder.RequireJS)
+	if err != nil {
+		t.Fatalf("ES6Import -> RequireJS failed: %v", err)
+	}
+	amdSource := apply(t, path, toAMD)
+	if err := os.WriteFile(path, []byte(amdSource), 0644); err != nil {
+		t.Fatalf("failed to write intermediate AMD file: %v", err)
+	}
+
+	backToES6, err := ConvertImportStyle(path, finder.RequireJS, finder.ES6Import)
+	if err != nil {
+		t.Fatalf("RequireJS -> ES6Import failed: %v", err)
+	}
+	want := "import React from 'react';\n  var { useState } = React;\nReact.render(useState);\n"
+	if got := apply(t, path, backToES6); got != want {
+		t.Errorf("unexpected round-trip result: got %q, want %q", got, want)
+	}
+}
+
+func TestConvertImportStyleRejectsUnsupportedAMDShapes(t *testing.T) {
+	path := writeTempFile(t, "// no define() call here\nconsole.log('hi');\n")
+
+	if _, err := ConvertImportStyle(path, finder.RequireJS, finder.ES6Import); err == nil {
+		t.Error("expected an error converting from RequireJS when the file has no define() call")
+	}
+}
@@ -0,0 +1,99 @@
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TraceEvent is a single Chrome Trace Event Format "complete event" (ph:"X"),
+// the form chrome://tracing and Perfetto both load directly. Ts and Dur are
+// in microseconds, per the format's spec.
+type TraceEvent struct {
+	Name string                 `json:"name"`
+	Ph   string                 `json:"ph"`
+	TS   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur"`
+	PID  int                    `json:"pid"`
+	TID  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// traceDocument is the top-level object Chrome Trace Event Format JSON
+// files are wrapped in.
+type traceDocument struct {
+	TraceEvents []TraceEvent `json:"traceEvents"`
+}
+
+// Trace collects per-file scan timings from Pipeline.Parse's worker pool so
+// callers can find slow files and hotspots, the way bundlers profile their
+// loaders. It's safe for concurrent use by multiple workers.
+type Trace struct {
+	mu     sync.Mutex
+	start  time.Time
+	events []TraceEvent
+}
+
+// NewTrace returns a Trace whose timestamps are relative to this call.
+func NewTrace() *Trace {
+	return &Trace{start: time.Now()}
+}
+
+// RecordScan appends a "scan" event covering one file's Parse-stage work:
+// the open+parse of path on worker tid, starting at start and running for
+// dur, having produced matchCount usages. cacheHit records whether the
+// result came from Pipeline's on-disk cache rather than a fresh parse.
+func (t *Trace) RecordScan(path string, tid int, start time.Time, dur time.Duration, matchCount int, cacheHit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, TraceEvent{
+		Name: "scan",
+		Ph:   "X",
+		TS:   start.Sub(t.start).Microseconds(),
+		Dur:  dur.Microseconds(),
+		PID:  1,
+		TID:  tid,
+		Args: map[string]interface{}{
+			"file":      path,
+			"matches":   matchCount,
+			"elapsedNs": dur.Nanoseconds(),
+			"cacheHit":  cacheHit,
+		},
+	})
+}
+
+// WriteJSON writes the collected events to w as Chrome Trace Event Format
+// JSON, loadable in chrome://tracing or Perfetto.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return json.NewEncoder(w).Encode(traceDocument{TraceEvents: t.events})
+}
+
+// Slowest returns up to n "scan" events sorted by descending duration.
+func (t *Trace) Slowest(n int) []TraceEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sorted := make([]TraceEvent, len(t.events))
+	copy(sorted, t.events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Dur > sorted[j].Dur })
+
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// WriteSummary prints the n slowest scanned files to w, one per line, for
+// a quick "what's slow" check without opening a trace viewer.
+func (t *Trace) WriteSummary(w io.Writer, n int) {
+	for i, event := range t.Slowest(n) {
+		file, _ := event.Args["file"].(string)
+		matches, _ := event.Args["matches"].(int)
+		fmt.Fprintf(w, "%d. %s (%.2fms, %d match(es))\n", i+1, file, float64(event.Dur)/1000, matches)
+	}
+}
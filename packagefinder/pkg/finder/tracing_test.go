@@ -0,0 +1,46 @@
+package finder
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTraceWriteJSONProducesChromeTraceEventFormat(t *testing.T) {
+	trace := NewTrace()
+	trace.RecordScan("a.ts", 0, time.Now(), 5*time.Millisecond, 3, false)
+	trace.RecordScan("b.ts", 1, time.Now(), 1*time.Millisecond, 0, true)
+
+	var buf bytes.Buffer
+	if err := trace.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var doc struct {
+		TraceEvents []TraceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteJSON produced invalid JSON: %v", err)
+	}
+	if len(doc.TraceEvents) != 2 {
+		t.Fatalf("expected 2 trace events, got %d", len(doc.TraceEvents))
+	}
+	if doc.TraceEvents[0].Name != "scan" || doc.TraceEvents[0].Ph != "X" {
+		t.Errorf("unexpected event shape: %+v", doc.TraceEvents[0])
+	}
+}
+
+func TestTraceSlowestOrdersByDescendingDuration(t *testing.T) {
+	trace := NewTrace()
+	trace.RecordScan("fast.ts", 0, time.Now(), 1*time.Millisecond, 0, false)
+	trace.RecordScan("slow.ts", 0, time.Now(), 9*time.Millisecond, 0, false)
+
+	slowest := trace.Slowest(1)
+	if len(slowest) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(slowest))
+	}
+	if file := slowest[0].Args["file"]; file != "slow.ts" {
+		t.Errorf("expected the slowest file first, got %v", file)
+	}
+}
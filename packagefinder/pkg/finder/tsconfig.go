@@ -0,0 +1,143 @@
+package finder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tsconfigJSON mirrors the subset of tsconfig.json this package understands.
+// json.Unmarshal tolerates (and ignores) comments are NOT supported here;
+// tsconfig files that rely on JSONC comments should be stripped upstream.
+type tsconfigJSON struct {
+	Extends         string `json:"extends"`
+	CompilerOptions struct {
+		BaseURL string              `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// TSConfigResolver resolves import specifiers against a project's
+// tsconfig.json `paths`/`baseUrl` alias configuration.
+type TSConfigResolver struct {
+	configDir string
+	baseURL   string
+	paths     map[string][]string
+}
+
+// NewTSConfigResolver walks up from startDir looking for the nearest
+// tsconfig.json, following `extends` chains (relative paths only), and
+// builds the alias map from `compilerOptions.paths`/`baseUrl`. It returns a
+// resolver with no aliases (never an error) when no tsconfig.json is found,
+// so callers can use it unconditionally.
+func NewTSConfigResolver(startDir string) (*TSConfigResolver, error) {
+	configPath, err := findNearestTSConfig(startDir)
+	if err != nil || configPath == "" {
+		return &TSConfigResolver{paths: map[string][]string{}}, nil
+	}
+
+	merged, err := loadTSConfigChain(configPath, map[string]bool{})
+	if err != nil {
+		return &TSConfigResolver{paths: map[string][]string{}}, nil
+	}
+
+	configDir := filepath.Dir(configPath)
+	baseURL := merged.CompilerOptions.BaseURL
+	if baseURL == "" {
+		baseURL = "."
+	}
+
+	return &TSConfigResolver{
+		configDir: configDir,
+		baseURL:   filepath.Join(configDir, baseURL),
+		paths:     merged.CompilerOptions.Paths,
+	}, nil
+}
+
+func findNearestTSConfig(dir string) (string, error) {
+	for {
+		candidate := filepath.Join(dir, "tsconfig.json")
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func loadTSConfigChain(configPath string, visited map[string]bool) (*tsconfigJSON, error) {
+	if visited[configPath] {
+		return &tsconfigJSON{}, nil
+	}
+	visited[configPath] = true
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg tsconfigJSON
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Extends != "" && strings.HasPrefix(cfg.Extends, ".") {
+		parentPath := filepath.Join(filepath.Dir(configPath), cfg.Extends)
+		if !strings.HasSuffix(parentPath, ".json") {
+			parentPath += ".json"
+		}
+		parent, err := loadTSConfigChain(parentPath, visited)
+		if err == nil {
+			if cfg.CompilerOptions.BaseURL == "" {
+				cfg.CompilerOptions.BaseURL = parent.CompilerOptions.BaseURL
+			}
+			if cfg.CompilerOptions.Paths == nil {
+				cfg.CompilerOptions.Paths = parent.CompilerOptions.Paths
+			} else {
+				for k, v := range parent.CompilerOptions.Paths {
+					if _, exists := cfg.CompilerOptions.Paths[k]; !exists {
+						cfg.CompilerOptions.Paths[k] = v
+					}
+				}
+			}
+		}
+	}
+
+	return &cfg, nil
+}
+
+// ResolveAlias attempts to resolve specifier against the `paths` alias map
+// (falling back to a plain baseUrl-relative lookup). It returns the
+// alias-resolved file path and true when specifier matched an alias pattern,
+// regardless of whether the target file actually exists on disk.
+func (r *TSConfigResolver) ResolveAlias(specifier string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+
+	for pattern, targets := range r.paths {
+		if len(targets) == 0 {
+			continue
+		}
+		if !strings.Contains(pattern, "*") {
+			if specifier == pattern {
+				return filepath.Join(r.baseURL, targets[0]), true
+			}
+			continue
+		}
+
+		prefix := strings.SplitN(pattern, "*", 2)[0]
+		suffix := strings.SplitN(pattern, "*", 2)[1]
+		if strings.HasPrefix(specifier, prefix) && strings.HasSuffix(specifier, suffix) {
+			captured := strings.TrimSuffix(strings.TrimPrefix(specifier, prefix), suffix)
+			target := strings.Replace(targets[0], "*", captured, 1)
+			return filepath.Join(r.baseURL, target), true
+		}
+	}
+
+	return "", false
+}
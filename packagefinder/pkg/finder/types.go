@@ -26,6 +26,8 @@ const (
 	GlobalVariable  ImportStyle = "GlobalVariable"
 	ImportMaps      ImportStyle = "ImportMaps"
 	UMD             ImportStyle = "UMD"
+	PathAlias       ImportStyle = "PathAlias"
+	SubpathImport   ImportStyle = "SubpathImport"
 	Unknown         ImportStyle = "Unknown"
 )
 
@@ -49,4 +51,21 @@ type PackageUsage struct {
 	IsDynamicImport   bool               `json:"isDynamicImport,omitempty"`
 	SymbolResolutions []SymbolResolution `json:"symbolResolutions,omitempty"`
 	SymbolUsages      []SymbolUsage      `json:"symbolUsages,omitempty"`
+	// MatchedField names how the specifier was resolved to packageName:
+	// "exports", "module", "main", "browser", "types", "imports" (a
+	// noderesolver.Resolution field), "tsconfig-path", or "importmap".
+	// Empty when the specifier matched packageName directly by name with no
+	// resolution step involved.
+	MatchedField string `json:"matchedField,omitempty"`
+	// ResolvedConditions records any condition keys consumed while
+	// resolving MatchedField: import-map keys ("imports", or
+	// "scopes:<prefix>") for MatchedField == "importmap", or the
+	// `exports`/`imports` condition path (e.g. ["node", "import"]) a
+	// noderesolver.Resolution walked through otherwise.
+	ResolvedConditions []string `json:"resolvedConditions,omitempty"`
+	// ParentURL is the second argument of the two-argument form of
+	// import.meta.resolve(specifier, parentURL), recorded verbatim (it may
+	// be a string literal or an expression like "import.meta.url"). Empty
+	// for the one-argument form and for every other ImportStyle.
+	ParentURL string `json:"parentURL,omitempty"`
 }